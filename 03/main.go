@@ -3,28 +3,115 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
+	"unicode"
 
 	"github.com/magejiCoder/magejiAoc/input"
+	"github.com/magejiCoder/magejiAoc/math"
 )
 
 type bank struct {
 	batteries []byte
-	max       int
-	maxs      map[int]int
 }
 
-func fromString(s string) bank {
+// selectMode decides whether a selection looks for the largest or the
+// smallest value.
+type selectMode int
+
+const (
+	selectMax selectMode = iota
+	selectMin
+)
+
+// selection describes which ordered subsequence of a bank should be picked.
+type selection struct {
+	length int
+	mode   selectMode
+	// minGap is the minimum index distance between two chosen batteries,
+	// 0 or 1 allows adjacent batteries to be chosen.
+	minGap int
+	// forbidden marks the positions that can not be chosen.
+	forbidden map[int]struct{}
+}
+
+func (s selection) gap() int {
+	if s.minGap < 1 {
+		return 1
+	}
+	return s.minGap
+}
+
+func (s selection) allowed(i int) bool {
+	_, ok := s.forbidden[i]
+	return !ok
+}
+
+// better reports whether a is preferred over b under the selection mode.
+func (s selection) better(a, b int) bool {
+	if s.mode == selectMin {
+		return a < b
+	}
+	return a > b
+}
+
+// ideal is the digit which can never be beaten under the selection mode.
+func (s selection) ideal() byte {
+	if s.mode == selectMin {
+		return 0
+	}
+	return 9
+}
+
+// bankFormat is the layout of a bank line.
+type bankFormat int
+
+const (
+	// formatDigits is the puzzle layout, one digit per battery without separators.
+	formatDigits bankFormat = iota
+	// formatFields separates batteries with whitespace, e.g. "9 8 7 6".
+	formatFields
+)
+
+func detectFormat(s string) bankFormat {
+	if strings.ContainsAny(s, " \t") {
+		return formatFields
+	}
+	return formatDigits
+}
+
+// parseBank parses one bank line into digit values (0-9), line is the 1-based
+// line number reported in errors, columns are counted in runes.
+func parseBank(line int, s string, format bankFormat) (bank, error) {
 	var bs []byte
-	for _, b := range s {
-		bs = append(bs, byte(b))
+	col := 0
+	prevDigit := false
+	for _, r := range s {
+		col++
+		if format == formatFields && unicode.IsSpace(r) {
+			prevDigit = false
+			continue
+		}
+		if r < '0' || r > '9' {
+			return bank{}, fmt.Errorf("line %d, column %d: %q is not a digit", line, col, r)
+		}
+		if format == formatFields && prevDigit {
+			return bank{}, fmt.Errorf("line %d, column %d: batteries must be separated by whitespace", line, col)
+		}
+		bs = append(bs, byte(r-'0'))
+		prevDigit = true
+	}
+	if len(bs) == 0 {
+		return bank{}, fmt.Errorf("line %d: empty bank", line)
 	}
 	return bank{
 		batteries: bs,
-		maxs:      make(map[int]int),
-	}
+	}, nil
 }
 
 func (b bank) pick2() int {
+	if len(b.batteries) < 2 {
+		return 0
+	}
 	max := buildInt(b.batteries[0], b.batteries[1])
 	for i := 0; i < len(b.batteries); i++ {
 		for j := i + 1; j < len(b.batteries); j++ {
@@ -37,77 +124,113 @@ func (b bank) pick2() int {
 	return max
 }
 
-func (b *bank) pickGreedy() int {
+// capacity returns, for every position i, how many batteries can still be
+// chosen from batteries[i:] under the gap and forbidden constraints.
+func (b bank) capacity(s selection) []int {
+	n := len(b.batteries)
+	g := s.gap()
+	c := make([]int, n+1)
+	for i := n - 1; i >= 0; i-- {
+		c[i] = c[i+1]
+		if !s.allowed(i) {
+			continue
+		}
+		take := 1 + c[min(i+g, n)]
+		if take > c[i] {
+			c[i] = take
+		}
+	}
+	return c
+}
+
+// pickGreedy picks the best s.length digit subsequence digit by digit, it
+// returns false if the bank can not provide enough batteries.
+func (b bank) pickGreedy(s selection) (int, bool) {
 	n := len(b.batteries)
-	const L = 12
-	if n < L {
-		b.max = 0
-		return b.max
+	g := s.gap()
+	c := b.capacity(s)
+	if s.length <= 0 || c[0] < s.length {
+		return 0, false
 	}
 
 	res := 0
 	start := 0
-	for k := range L {
-		// The farthest we can start searching so that we still have enough digits left:
-		// we must leave (L-k-1) digits after our choice.
-		end := n - (L - k - 1)
-		bestDigit := byte('0' - 1)
-		bestPos := start
-		for i := start; i < end; i++ {
+	for k := range s.length {
+		// we must leave enough room for the (length-k-1) digits after our choice.
+		need := s.length - k - 1
+		bestPos := -1
+		for i := start; i < n; i++ {
+			if !s.allowed(i) {
+				continue
+			}
+			// capacity never grows to the right, so no later position fits either.
+			if c[min(i+g, n)] < need {
+				break
+			}
 			d := b.batteries[i]
-			if d > bestDigit {
-				bestDigit = d
+			if bestPos == -1 || s.better(int(d), int(b.batteries[bestPos])) {
 				bestPos = i
-				// Early exit if we find '9'
-				if bestDigit == '9' {
+				// Early exit if we find the unbeatable digit
+				if d == s.ideal() {
 					break
 				}
 			}
 		}
-		res = res*10 + int(bestDigit-'0')
-		start = bestPos + 1
+		res = res*10 + int(b.batteries[bestPos])
+		start = bestPos + g
 	}
-	b.max = res
-	return b.max
+	return res, true
 }
 
-func (b *bank) pick(raw []byte, cur []byte) {
-	// fmt.Printf("raw: %s,cur: %s\n", string(raw), string(cur))
-	if len(cur) == 12 {
-		if input.Atoi(string(cur)) > b.max {
-			// fmt.Printf("current: %s\n", string(cur))
-			b.max = input.Atoi(string(cur))
-		}
-		return
-	}
-	if len(raw) == 0 {
-		return
+// pickDP solves the same selection as pickGreedy with a suffix DP in
+// O(n * length), it is the reference the greedy can be checked against.
+func (b bank) pickDP(s selection) (int, bool) {
+	n := len(b.batteries)
+	g := s.gap()
+	L := s.length
+	if L <= 0 {
+		return 0, false
 	}
-	if v, ok := b.maxs[len(cur)]; ok && v > input.Atoi(string(cur)) {
-		// drop
-		b.pick(raw[1:], cur)
-		return
+	// best[i][r] is the best r digit value that can be picked from batteries[i:],
+	// ok[i][r] tells whether such a value exists at all.
+	best := make([][]int, n+1)
+	ok := make([][]bool, n+1)
+	for i := range best {
+		best[i] = make([]int, L+1)
+		ok[i] = make([]bool, L+1)
+		ok[i][0] = true
 	}
-	c := append(cur, raw[0])
-	if v, ok := b.maxs[len(c)]; !ok || v <= input.Atoi(string(c)) {
-		// accept
-		b.pick(raw[1:], c)
-		b.maxs[len(c)] = input.Atoi(string(c))
+	for i := n - 1; i >= 0; i-- {
+		next := min(i+g, n)
+		for r := 1; r <= L; r++ {
+			best[i][r], ok[i][r] = best[i+1][r], ok[i+1][r]
+			if !s.allowed(i) || !ok[next][r-1] {
+				continue
+			}
+			v := int(b.batteries[i])*math.Power(10, r-1) + best[next][r-1]
+			if !ok[i][r] || s.better(v, best[i][r]) {
+				best[i][r], ok[i][r] = v, true
+			}
+		}
 	}
-	// drop
-	b.pick(raw[1:], cur)
+	return best[0][L], ok[0][L]
 }
 
-func buildInt(r1, r2 byte) int {
-	return input.Atoi(string(r1) + string(r2))
+func buildInt(d1, d2 byte) int {
+	return int(d1)*10 + int(d2)
 }
 
 func p1() {
 	txt := input.NewTXTFile("input.txt")
 	ctx := context.TODO()
 	var sum int
+	var ln int
 	txt.ReadByLine(ctx, func(line string) error {
-		s := fromString(line)
+		ln++
+		s, err := parseBank(ln, line, detectFormat(line))
+		if err != nil {
+			panic(err)
+		}
 		max := s.pick2()
 		sum += max
 		return nil
@@ -120,9 +243,15 @@ func p2() {
 	txt := input.NewTXTFile("input.txt")
 	ctx := context.TODO()
 	var sum int
+	var ln int
 	txt.ReadByLine(ctx, func(line string) error {
-		s := fromString(line)
-		sum += s.pickGreedy()
+		ln++
+		s, err := parseBank(ln, line, detectFormat(line))
+		if err != nil {
+			panic(err)
+		}
+		max, _ := s.pickGreedy(selection{length: 12})
+		sum += max
 		return nil
 	})
 
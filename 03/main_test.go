@@ -0,0 +1,106 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// TestGreedyMatchesDP checks pickGreedy against pickDP on random banks and
+// selections in every mode.
+func TestGreedyMatchesDP(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for range 5000 {
+		b := bank{batteries: make([]byte, 1+r.Intn(20))}
+		for i := range b.batteries {
+			b.batteries[i] = byte(r.Intn(10))
+		}
+		s := selection{
+			length: r.Intn(8),
+			mode:   selectMode(r.Intn(2)),
+			minGap: r.Intn(4),
+		}
+		if r.Intn(2) == 0 {
+			s.forbidden = make(map[int]struct{})
+			for range r.Intn(len(b.batteries) + 1) {
+				s.forbidden[r.Intn(len(b.batteries))] = struct{}{}
+			}
+		}
+		greedy, gok := b.pickGreedy(s)
+		dp, dok := b.pickDP(s)
+		if greedy != dp || gok != dok {
+			t.Fatalf("bank %v, selection %+v: greedy %d %v, dp %d %v", b.batteries, s, greedy, gok, dp, dok)
+		}
+	}
+}
+
+func TestPick2(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for range 1000 {
+		b := bank{batteries: make([]byte, 2+r.Intn(15))}
+		for i := range b.batteries {
+			b.batteries[i] = byte(r.Intn(10))
+		}
+		want, _ := b.pickDP(selection{length: 2})
+		if got := b.pick2(); got != want {
+			t.Fatalf("bank %v: pick2 %d, want %d", b.batteries, got, want)
+		}
+	}
+}
+
+func TestExample(t *testing.T) {
+	tests := []struct {
+		line        string
+		two, twelve int
+	}{
+		{"987654321111111", 98, 987654321111},
+		{"811111111111119", 89, 811111111119},
+		{"234234234234278", 78, 434234234278},
+		{"818181911112111", 92, 888911112111},
+	}
+	for _, tt := range tests {
+		b, err := parseBank(1, tt.line, detectFormat(tt.line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := b.pick2(); got != tt.two {
+			t.Errorf("%s: pick2 %d, want %d", tt.line, got, tt.two)
+		}
+		if got, _ := b.pickGreedy(selection{length: 12}); got != tt.twelve {
+			t.Errorf("%s: pickGreedy %d, want %d", tt.line, got, tt.twelve)
+		}
+	}
+}
+
+func TestParseBank(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []byte
+		wantErr string
+	}{
+		{in: "987", want: []byte{9, 8, 7}},
+		{in: "9 8 7", want: []byte{9, 8, 7}},
+		{in: " 9\t8  7 ", want: []byte{9, 8, 7}},
+		{in: "12a4", wantErr: `line 4, column 3: 'a' is not a digit`},
+		{in: "1é3", wantErr: `line 4, column 2: 'é' is not a digit`},
+		{in: "98 7", wantErr: "line 4, column 2: batteries must be separated by whitespace"},
+		{in: "", wantErr: "line 4: empty bank"},
+		{in: "   ", wantErr: "line 4: empty bank"},
+	}
+	for _, tt := range tests {
+		b, err := parseBank(4, tt.in, detectFormat(tt.in))
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("parseBank(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseBank(%q): %v", tt.in, err)
+			continue
+		}
+		if !slices.Equal(b.batteries, tt.want) {
+			t.Errorf("parseBank(%q) = %v, want %v", tt.in, b.batteries, tt.want)
+		}
+	}
+}
@@ -2,31 +2,57 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/magejiCoder/magejiAoc/grid"
 	"github.com/magejiCoder/magejiAoc/input"
+	"github.com/scbizu/aoc2025/internal/dense"
 )
 
 type paperGrid struct {
-	m      grid.VecMatrix[byte]
-	papers map[grid.Vec]struct{}
+	m      *dense.Grid[byte]
+	papers *dense.Grid[bool]
+	// rolls is the number of papers still on the grid.
+	rolls int
+
+	nb neighbourhood
+	// a roll is accessible when it has fewer than threshold neighbours.
+	threshold int
 }
 
-func NewGrid() paperGrid {
-	return paperGrid{
-		m:      grid.NewVecMatrix[byte](),
-		papers: make(map[grid.Vec]struct{}),
+// NewCustomGrid returns the grid of rolls in m, the puzzle uses the moore
+// neighbourhood and a threshold of 4.
+func NewCustomGrid(m *dense.Grid[byte], nb neighbourhood, threshold int) paperGrid {
+	pg := paperGrid{
+		m:         m,
+		papers:    dense.New[bool](m.Width(), m.Height()),
+		nb:        nb,
+		threshold: threshold,
+	}
+	for v, c := range m.All() {
+		if c == '@' {
+			pg.papers.Set(v, true)
+			pg.rolls++
+		}
 	}
+	return pg
 }
 
-func (pg *paperGrid) clean() (int, map[grid.Vec]struct{}) {
-	if len(pg.papers) == 0 {
-		return 0, nil
-	}
+// neighbourhood is the list of offsets counted as neighbours of a cell.
+type neighbourhood []grid.Vec
 
-	paperSet := pg.papers
-	deltas := [8]grid.Vec{
+var (
+	vonNeumann = neighbourhood{
+		{X: 0, Y: -1},
+		{X: -1, Y: 0},
+		{X: 1, Y: 0},
+		{X: 0, Y: 1},
+	}
+	moore = neighbourhood{
 		{X: -1, Y: -1},
 		{X: 0, Y: -1},
 		{X: 1, Y: -1},
@@ -36,21 +62,75 @@ func (pg *paperGrid) clean() (int, map[grid.Vec]struct{}) {
 		{X: 0, Y: 1},
 		{X: 1, Y: 1},
 	}
+	// hex treats the grid as axial hex coordinates, where the six neighbours
+	// of a cell are fixed offsets.
+	hex = neighbourhood{
+		{X: 1, Y: 0},
+		{X: 1, Y: -1},
+		{X: 0, Y: -1},
+		{X: -1, Y: 0},
+		{X: -1, Y: 1},
+		{X: 0, Y: 1},
+	}
+)
+
+// parseNeighbourhood accepts "moore", "von-neumann", "hex" or a custom list of
+// offsets such as "0,-1 -1,0 1,0 0,1".
+func parseNeighbourhood(s string) (neighbourhood, error) {
+	switch s {
+	case "moore":
+		return moore, nil
+	case "von-neumann":
+		return vonNeumann, nil
+	case "hex":
+		return hex, nil
+	}
+	var nb neighbourhood
+	for f := range strings.FieldsSeq(s) {
+		parts := strings.Split(f, ",")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid offset %q", f)
+		}
+		x, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset %q: %w", f, err)
+		}
+		y, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset %q: %w", f, err)
+		}
+		if x == 0 && y == 0 {
+			return nil, fmt.Errorf("offset %q points at the cell itself", f)
+		}
+		nb = append(nb, grid.Vec{X: x, Y: y})
+	}
+	if len(nb) == 0 {
+		return nil, fmt.Errorf("empty neighbourhood %q", s)
+	}
+	return nb, nil
+}
+
+func (pg *paperGrid) clean() (int, map[grid.Vec]struct{}) {
+	if pg.rolls == 0 {
+		return 0, nil
+	}
 
 	pp := 0
 	removed := make(map[grid.Vec]struct{})
-	for v := range pg.papers {
+	for v, paper := range pg.papers.All() {
+		if !paper {
+			continue
+		}
 		cnt := 0
-		for _, d := range deltas {
-			n := grid.Vec{X: v.X + d.X, Y: v.Y + d.Y}
-			if _, ok := paperSet[n]; ok {
+		for _, n := range pg.papers.Neighbours(v, pg.nb) {
+			if n {
 				cnt++
-				if cnt >= 4 {
+				if cnt >= pg.threshold {
 					break
 				}
 			}
 		}
-		if cnt < 4 {
+		if cnt < pg.threshold {
 			removed[v] = struct{}{}
 			pp++
 		}
@@ -58,68 +138,121 @@ func (pg *paperGrid) clean() (int, map[grid.Vec]struct{}) {
 	return pp, removed
 }
 
-func p1() {
+// peel keeps removing accessible rolls until none is left and returns how
+// many were removed, along with the round (starting at 1) each removed roll
+// would have been removed in by repeated clean calls. Instead of rescanning
+// every roll per round it keeps a neighbour count per roll and only enqueues
+// the neighbours of a removed roll once they drop below the threshold, so
+// the rolls left behind are the k-core of the grid.
+func (pg *paperGrid) peel() (int, map[grid.Vec]int) {
+	count := dense.New[int](pg.papers.Width(), pg.papers.Height())
+	rounds := make(map[grid.Vec]int)
+	var queue []grid.Vec
+	for v, paper := range pg.papers.All() {
+		if !paper {
+			continue
+		}
+		var cnt int
+		for _, n := range pg.papers.Neighbours(v, pg.nb) {
+			if n {
+				cnt++
+			}
+		}
+		count.Set(v, cnt)
+		if cnt < pg.threshold {
+			queue = append(queue, v)
+			rounds[v] = 1
+		}
+	}
+
+	// a custom neighbourhood may be asymmetric, so walk it backwards to find
+	// the rolls that counted a removed one.
+	back := make(neighbourhood, len(pg.nb))
+	for i, d := range pg.nb {
+		back[i] = grid.Vec{X: -d.X, Y: -d.Y}
+	}
+	// the queue is FIFO, so every roll of a round is removed before the
+	// rolls it unlocks, which belong to the next round.
+	var removed int
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		pg.papers.Set(v, false)
+		pg.rolls--
+		removed++
+		for n, paper := range pg.papers.Neighbours(v, back) {
+			if !paper {
+				continue
+			}
+			cnt, _ := count.Get(n)
+			count.Set(n, cnt-1)
+			// only the step below the threshold enqueues, rolls already below it are queued.
+			if cnt-1 == pg.threshold-1 {
+				queue = append(queue, n)
+				rounds[n] = rounds[v] + 1
+			}
+		}
+	}
+	return removed, rounds
+}
+
+func p1(nb neighbourhood, threshold int) {
 	t := input.NewTXTFile("input.txt")
 	ctx := context.TODO()
-	g := NewGrid()
-	var y int
+	var lines []string
 	t.ReadByLine(ctx, func(line string) error {
-		for x := 0; x < len(line); x++ {
-			g.m.Add(grid.Vec{
-				X: x,
-				Y: y,
-			}, line[x])
-			if line[x] == '@' {
-				g.papers[grid.Vec{
-					X: x,
-					Y: y,
-				}] = struct{}{}
-			}
-		}
-		y++
+		lines = append(lines, line)
 		return nil
 	})
+	m, err := dense.Parse(lines)
+	if err != nil {
+		panic(err)
+	}
+	g := NewCustomGrid(m, nb, threshold)
 	pp, _ := g.clean()
 	fmt.Printf("p1: %d\n", pp)
 }
 
-func p2() {
+func p2(nb neighbourhood, threshold int, heatmap bool, gifPath string) {
 	t := input.NewTXTFile("input.txt")
 	ctx := context.TODO()
-	g := NewGrid()
-	var y int
+	var lines []string
 	t.ReadByLine(ctx, func(line string) error {
-		for x := 0; x < len(line); x++ {
-			g.m.Add(grid.Vec{
-				X: x,
-				Y: y,
-			}, line[x])
-			if line[x] == '@' {
-				g.papers[grid.Vec{
-					X: x,
-					Y: y,
-				}] = struct{}{}
-			}
-		}
-		y++
+		lines = append(lines, line)
 		return nil
 	})
-	var total int
-	for {
-		rc, removed := g.clean()
-		if rc == 0 {
-			break
+	m, err := dense.Parse(lines)
+	if err != nil {
+		panic(err)
+	}
+	g := NewCustomGrid(m, nb, threshold)
+	total, rounds := g.peel()
+	fmt.Printf("p2: %d\n", total)
+	if heatmap {
+		fmt.Print(g.heatmap(rounds))
+	}
+	if gifPath != "" {
+		f, err := os.Create(gifPath)
+		if err != nil {
+			panic(err)
 		}
-		// fmt.Printf("removed: %d\n", rc)
-		total += rc
-		for v := range removed {
-			delete(g.papers, v)
+		defer f.Close()
+		if err := g.writeGIF(f, rounds); err != nil {
+			panic(err)
 		}
 	}
-	fmt.Printf("p2: %d\n", total)
 }
 
 func main() {
-	p1()
-	p2()
+	nbFlag := flag.String("neighbourhood", "moore", `"moore", "von-neumann", "hex" or offsets like "0,-1 -1,0 1,0 0,1"`)
+	threshold := flag.Int("threshold", 4, "a roll is accessible with fewer neighbours than this")
+	heatmap := flag.Bool("heatmap", false, "print the round every roll is removed in")
+	gifPath := flag.String("gif", "", "write the removal rounds as an animated GIF to this path")
+	flag.Parse()
+	nb, err := parseNeighbourhood(*nbFlag)
+	if err != nil {
+		panic(err)
+	}
+	p1(nb, *threshold)
+	p2(nb, *threshold, *heatmap, *gifPath)
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"strings"
+
+	"github.com/magejiCoder/magejiAoc/grid"
+)
+
+// cellSize is the edge length in pixels of one grid cell in the GIF.
+const cellSize = 4
+
+const roundRunes = "123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// roundRune labels a removal round with a single character, rounds past the
+// alphabet are shown as '+'.
+func roundRune(r int) byte {
+	if r < 1 || r > len(roundRunes) {
+		return '+'
+	}
+	return roundRunes[r-1]
+}
+
+// heatmap renders the grid with every removed roll replaced by the round it
+// was removed in, rolls that are never removed stay '@'.
+func (pg paperGrid) heatmap(rounds map[grid.Vec]int) string {
+	var b strings.Builder
+	for y := 0; y < pg.m.Height(); y++ {
+		for x, c := range pg.m.Row(y) {
+			if r, ok := rounds[grid.Vec{X: x, Y: y}]; ok {
+				c = roundRune(r)
+			}
+			b.WriteByte(c)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+const (
+	colorEmpty = iota
+	colorRoll
+	colorRemoving
+	colorRemoved
+)
+
+var framePalette = color.Palette{
+	colorEmpty:    color.White,
+	colorRoll:     color.RGBA{R: 0x40, G: 0x40, B: 0x40, A: 0xff},
+	colorRemoving: color.RGBA{R: 0xe0, G: 0x20, B: 0x20, A: 0xff},
+	colorRemoved:  color.RGBA{R: 0xf0, G: 0xc8, B: 0xc8, A: 0xff},
+}
+
+// writeGIF animates the removal, frame 0 is the initial grid and frame r
+// highlights the rolls removed in round r.
+func (pg paperGrid) writeGIF(w io.Writer, rounds map[grid.Vec]int) error {
+	var last int
+	for _, r := range rounds {
+		last = max(last, r)
+	}
+	anim := &gif.GIF{}
+	bounds := image.Rect(0, 0, pg.m.Width()*cellSize, pg.m.Height()*cellSize)
+	for frame := 0; frame <= last; frame++ {
+		img := image.NewPaletted(bounds, framePalette)
+		for v, c := range pg.m.All() {
+			if c != '@' {
+				continue
+			}
+			idx := uint8(colorRoll)
+			if r, ok := rounds[v]; ok && r < frame {
+				idx = colorRemoved
+			} else if ok && r == frame {
+				idx = colorRemoving
+			}
+			for dy := 0; dy < cellSize; dy++ {
+				for dx := 0; dx < cellSize; dx++ {
+					img.SetColorIndex(v.X*cellSize+dx, v.Y*cellSize+dy, idx)
+				}
+			}
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, 20)
+	}
+	// hold the final state a little longer before looping.
+	anim.Delay[len(anim.Delay)-1] = 200
+	return gif.EncodeAll(w, anim)
+}
@@ -3,79 +3,45 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 
 	"github.com/magejiCoder/magejiAoc/input"
+	"github.com/scbizu/aoc2025/internal/interval"
 )
 
-type ingRange struct {
-	start, end int
-}
-
-func (r ingRange) IsInRange(n int) bool {
-	return n >= r.start && n <= r.end
-}
-
-func (r ingRange) merge(r2 ingRange) ingRange {
-	nr := r
-	if r2.start < r.start {
-		nr.start = r2.start
-	}
-	if r2.end > r.end {
-		nr.end = r2.end
-	}
-	return nr
-}
-
-func countLen(rg ingRange) int {
-	return rg.end - rg.start + 1
-}
-
 func p2() {
 	txt := input.NewTXTFile("input.txt")
 	ctx := context.TODO()
-	var rgs []ingRange
+	var rgs []interval.Interval
 	txt.ReadByBlock(ctx, "\n\n", func(block []string) error {
 		rangeParts := block[0]
 		for rp := range strings.SplitSeq(rangeParts, "\n") {
 			l, r := mustParseRange(rp)
-			rgs = append(rgs, ingRange{
-				start: l,
-				end:   r,
+			rgs = append(rgs, interval.Interval{
+				Start: l,
+				End:   r,
 			})
 		}
 		return nil
 	})
-	nrangs := make(map[ingRange]struct{}, len(rgs))
-	for _, rg := range rgs {
-		for exg := range nrangs {
-			if rg.IsInRange(exg.start) || rg.IsInRange(exg.end) || exg.IsInRange(rg.start) || exg.IsInRange(rg.end) {
-				rg = rg.merge(exg)
-				delete(nrangs, exg)
-			}
-		}
-		nrangs[rg] = struct{}{}
-	}
-	// fmt.Printf("ranges : %v\n", nrangs)
-	var all int
-	for r := range nrangs {
-		all += countLen(r)
-	}
-	fmt.Printf("p2: %d\n", all)
+	fresh := interval.New(rgs...)
+	fmt.Printf("p2: %d\n", fresh.Length())
 }
 
 func p1() {
 	txt := input.NewTXTFile("input.txt")
 	ctx := context.TODO()
-	var rgs []ingRange
+	var rgs []interval.Interval
 	var ingIds []int
 	txt.ReadByBlock(ctx, "\n\n", func(block []string) error {
 		rangeParts := block[0]
 		for rp := range strings.SplitSeq(rangeParts, "\n") {
 			l, r := mustParseRange(rp)
-			rgs = append(rgs, ingRange{
-				start: l,
-				end:   r,
+			rgs = append(rgs, interval.Interval{
+				Start: l,
+				End:   r,
 			})
 		}
 		ids := block[1]
@@ -84,25 +50,77 @@ func p1() {
 		}
 		return nil
 	})
+	fresh := interval.New(rgs...)
 	var i int
 	for _, id := range ingIds {
-		for _, r := range rgs {
-			if r.IsInRange(id) {
-				i++
-				break
-			}
+		if fresh.Contains(id) {
+			i++
 		}
 	}
 	fmt.Printf("p1: %d\n", i)
 }
 
+// parseRange parses "l-r", where both bounds may be negative, e.g. "-5--3".
+// Inverted ranges are rejected.
+func parseRange(raw string) (interval.Interval, error) {
+	// a leading '-' is the sign of l, so the separator is the next one.
+	i := strings.Index(raw[min(1, len(raw)):], "-") + 1
+	if i == 0 {
+		return interval.Interval{}, fmt.Errorf("range %q: missing '-' separator", raw)
+	}
+	l, err := strconv.Atoi(raw[:i])
+	if err != nil {
+		return interval.Interval{}, fmt.Errorf("range %q: invalid start %q", raw, raw[:i])
+	}
+	r, err := strconv.Atoi(raw[i+1:])
+	if err != nil {
+		return interval.Interval{}, fmt.Errorf("range %q: invalid end %q", raw, raw[i+1:])
+	}
+	if l > r {
+		return interval.Interval{}, fmt.Errorf("range %q is inverted: start %d > end %d", raw, l, r)
+	}
+	return interval.Interval{Start: l, End: r}, nil
+}
+
 func mustParseRange(raw string) (int, int) {
-	parts := strings.Split(raw, "-")
-	l, r := parts[0], parts[1]
-	return input.Atoi(l), input.Atoi(r)
+	rg, err := parseRange(raw)
+	if err != nil {
+		panic(err)
+	}
+	return rg.Start, rg.End
 }
 
 func main() {
+	// query [file] answers the queries read from stdin, see query.
+	if len(os.Args) > 1 && os.Args[1] == "query" {
+		path := "input.txt"
+		if len(os.Args) > 2 {
+			path = os.Args[2]
+		}
+		if err := query(loadInventory(path), os.Stdin, os.Stdout); err != nil {
+			panic(err)
+		}
+		return
+	}
+	// stream [file] solves both parts in one pass for files too big to load.
+	if len(os.Args) > 1 && os.Args[1] == "stream" {
+		path := "input.txt"
+		if len(os.Args) > 2 {
+			path = os.Args[2]
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		fresh, count, err := stream(f)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("p1: %d\n", count)
+		fmt.Printf("p2: %d\n", fresh.Length())
+		return
+	}
 	p1()
 	p2()
 }
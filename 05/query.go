@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/magejiCoder/magejiAoc/input"
+	"github.com/scbizu/aoc2025/internal/interval"
+)
+
+// ingRange is a fresh range as listed in the input, line is its 1-based
+// line number.
+type ingRange struct {
+	interval.Interval
+	line int
+}
+
+// inventory answers queries against the fresh ranges of the database.
+type inventory struct {
+	// ranges are the input ranges sorted by start.
+	ranges []ingRange
+	// maxEnd[i] is the largest end among ranges[:i+1].
+	maxEnd []int
+	fresh  *interval.IntervalSet
+}
+
+func newInventory(rgs []ingRange) inventory {
+	inv := inventory{
+		ranges: append([]ingRange(nil), rgs...),
+		maxEnd: make([]int, len(rgs)),
+	}
+	sort.SliceStable(inv.ranges, func(i, j int) bool {
+		return inv.ranges[i].Start < inv.ranges[j].Start
+	})
+	ivs := make([]interval.Interval, 0, len(rgs))
+	for i, r := range inv.ranges {
+		inv.maxEnd[i] = r.End
+		if i > 0 {
+			inv.maxEnd[i] = max(inv.maxEnd[i], inv.maxEnd[i-1])
+		}
+		ivs = append(ivs, r.Interval)
+	}
+	inv.fresh = interval.New(ivs...)
+	return inv
+}
+
+// loadInventory reads the ranges section of a database file, the ID section
+// after the blank line is optional.
+func loadInventory(path string) inventory {
+	txt := input.NewTXTFile(path)
+	var rgs []ingRange
+	txt.ReadByBlock(context.TODO(), "\n\n", func(block []string) error {
+		for i, rp := range strings.Split(block[0], "\n") {
+			l, r := mustParseRange(rp)
+			rgs = append(rgs, ingRange{
+				Interval: interval.Interval{Start: l, End: r},
+				line:     i + 1,
+			})
+		}
+		return nil
+	})
+	return newInventory(rgs)
+}
+
+// covering returns the input ranges that contain id, ordered by start.
+func (inv inventory) covering(id int) []ingRange {
+	// ranges after hi start past id, and once maxEnd drops below id no range
+	// further left can reach it either.
+	hi := sort.Search(len(inv.ranges), func(i int) bool {
+		return inv.ranges[i].Start > id
+	})
+	var res []ingRange
+	for i := hi - 1; i >= 0 && inv.maxEnd[i] >= id; i-- {
+		if inv.ranges[i].Contains(id) {
+			res = append(res, inv.ranges[i])
+		}
+	}
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res
+}
+
+// freshIn counts the fresh IDs in [a, b].
+func (inv inventory) freshIn(a, b int) *big.Int {
+	return inv.fresh.Intersect(interval.New(interval.Interval{Start: a, End: b})).Length()
+}
+
+// gaps returns the spoiled stretches between the merged fresh ranges.
+func (inv inventory) gaps() []interval.Interval {
+	ivs := inv.fresh.Intervals()
+	if len(ivs) == 0 {
+		return nil
+	}
+	return inv.fresh.Complement(interval.Interval{
+		Start: ivs[0].Start,
+		End:   ivs[len(ivs)-1].End,
+	}).Intervals()
+}
+
+// query runs one query per line of r against inv:
+//
+//	cover <id>    the input ranges containing id
+//	fresh <a> <b> how many IDs in [a, b] are fresh
+//	gaps          the spoiled stretches between the fresh ranges
+//
+// a bad query is reported and skipped.
+func query(inv inventory, r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		args, err := atois(fields[1:])
+		if err != nil {
+			fmt.Fprintf(w, "error: %v\n", err)
+			continue
+		}
+		switch {
+		case fields[0] == "cover" && len(args) == 1:
+			rgs := inv.covering(args[0])
+			if len(rgs) == 0 {
+				fmt.Fprintf(w, "%d: spoiled\n", args[0])
+				continue
+			}
+			for _, rg := range rgs {
+				fmt.Fprintf(w, "%d: %d-%d (line %d)\n", args[0], rg.Start, rg.End, rg.line)
+			}
+		case fields[0] == "fresh" && len(args) == 2:
+			fmt.Fprintf(w, "%d-%d: %d fresh\n", args[0], args[1], inv.freshIn(args[0], args[1]))
+		case fields[0] == "gaps" && len(args) == 0:
+			for _, g := range inv.gaps() {
+				fmt.Fprintf(w, "%d-%d\n", g.Start, g.End)
+			}
+		default:
+			fmt.Fprintf(w, "error: unknown query %q\n", sc.Text())
+		}
+	}
+	return sc.Err()
+}
+
+func atois(fields []string) ([]int, error) {
+	ns := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", f)
+		}
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/scbizu/aoc2025/internal/interval"
+)
+
+// stream reads a database in one pass: the ranges section is merged into the
+// interval set as it is read, then every ID is checked and dropped, so only
+// the ranges are held in memory. It returns the set and the number of fresh
+// IDs.
+func stream(r io.Reader) (*interval.IntervalSet, int, error) {
+	sc := bufio.NewScanner(r)
+	fresh := interval.New()
+	var ids bool
+	var ln, count int
+	for sc.Scan() {
+		ln++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			ids = true
+			continue
+		}
+		if !ids {
+			rg, err := parseRange(line)
+			if err != nil {
+				return nil, 0, fmt.Errorf("line %d: %w", ln, err)
+			}
+			fresh.Add(rg)
+			continue
+		}
+		id, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: invalid ID %q", ln, line)
+		}
+		if fresh.Contains(id) {
+			count++
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, 0, err
+	}
+	return fresh, count, nil
+}
@@ -2,48 +2,136 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/magejiCoder/magejiAoc/input"
+	"github.com/scbizu/aoc2025/06/worksheet"
 )
 
 type calculator struct {
-	op      byte
+	op      string
 	numbers []int
+	// column is the 1-based character column the problem starts at, the
+	// same column the worksheet errors report.
+	column int
 }
 
-func (c calculator) calc() int {
-	// fmt.Printf("numbers: %v,op: %s\n", c.numbers, string(c.op))
-	var sum int
-	switch c.op {
-	case '+':
-		for _, n := range c.numbers {
-			sum += n
+// calc folds the numbers with the registered operator. It works on ints
+// while the result fits and carries on with math/big from the first step
+// that overflows.
+func (c calculator) calc() (*big.Int, error) {
+	op, ok := operators[c.op]
+	if !ok {
+		return nil, fmt.Errorf("column %d: unknown operator %q", c.column, c.op)
+	}
+	if len(c.numbers) == 0 {
+		return nil, fmt.Errorf("column %d: no numbers", c.column)
+	}
+	acc := c.numbers[0]
+	for i, n := range c.numbers[1:] {
+		next, ok := op.fold(acc, n)
+		if !ok {
+			return c.calcBig(op, big.NewInt(int64(acc)), c.numbers[i+1:])
 		}
-	case '*':
-		sum = 1
-		for _, n := range c.numbers {
-			sum *= n
+		acc = next
+	}
+	return big.NewInt(int64(acc)), nil
+}
+
+func (c calculator) calcBig(op operator, acc *big.Int, numbers []int) (*big.Int, error) {
+	for _, n := range numbers {
+		var err error
+		acc, err = op.bigFold(acc, big.NewInt(int64(n)))
+		if err != nil {
+			return nil, fmt.Errorf("column %d: %w", c.column, err)
 		}
 	}
+	return acc, nil
+}
+
+// result is one solved problem of a worksheet.
+type result struct {
+	calculator
+	value *big.Int
+}
+
+// solve solves every problem of the worksheet, reading the numbers in the
+// given orientation. Results are in worksheet order, left to right.
+func solve(rows []string, o worksheet.Orientation) []result {
+	problems, err := worksheet.Parse(rows)
+	if err != nil {
+		panic(err)
+	}
+	var results []result
+	for _, p := range problems {
+		numbers, err := p.Numbers(o)
+		if err != nil {
+			panic(err)
+		}
+		c := calculator{
+			op:      p.Op,
+			numbers: numbers,
+			column:  p.Column + 1,
+		}
+		v, err := c.calc()
+		if err != nil {
+			panic(err)
+		}
+		results = append(results, result{
+			calculator: c,
+			value:      v,
+		})
+	}
+	return results
+}
+
+func total(results []result) *big.Int {
+	sum := new(big.Int)
+	for _, r := range results {
+		sum.Add(sum, r.value)
+	}
 	return sum
 }
 
-func resolveRow(r string) []string {
-	parts := strings.SplitSeq(r, " ")
-	var row []string
-	for part := range parts {
-		part = strings.ReplaceAll(part, " ", "")
-		if part == "" {
-			continue
+// printBreakdown writes one line per problem: its column, operator, numbers
+// in reading order and result.
+func printBreakdown(w io.Writer, results []result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "column\top\tnumbers\tresult\t")
+	for _, r := range results {
+		ns := make([]string, len(r.numbers))
+		for i, n := range r.numbers {
+			ns[i] = strconv.Itoa(n)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t\n", r.column, r.op, strings.Join(ns, " "), r.value)
+	}
+	tw.Flush()
+}
+
+// render writes the problems as a worksheet read in orientation o.
+func render(cs []calculator, o worksheet.Orientation) ([]string, error) {
+	problems := make([]worksheet.Problem, 0, len(cs))
+	for _, c := range cs {
+		p, err := worksheet.FromNumbers(c.numbers, c.op, o)
+		if err != nil {
+			return nil, fmt.Errorf("column %d: %w", c.column, err)
 		}
-		row = append(row, part)
+		problems = append(problems, p)
 	}
-	return row
+	return worksheet.Format(problems), nil
 }
 
-func p1() {
+// convert rewrites the input worksheet into orientation to, the input is
+// read in the other orientation. Only the numbers are converted, the
+// problems are not solved.
+func convert(to worksheet.Orientation) {
 	t := input.NewTXTFile("input.txt")
 	ctx := context.TODO()
 	var rows []string
@@ -51,32 +139,20 @@ func p1() {
 		rows = append(rows, line)
 		return nil
 	})
-	problems := make(map[int][]string)
-	lastRow := len(rows) - 1
-	for _, r := range rows {
-		parts := resolveRow(r)
-		for i, p := range parts {
-			problems[i] = append(problems[i], p)
-		}
+	from := worksheet.Human
+	if to == worksheet.Human {
+		from = worksheet.Cephalopod
 	}
-	var sum int
-	for _, problem := range problems {
-		c := calculator{}
-		var numbers []int
-		for i, p := range problem {
-			if i == lastRow {
-				continue
-			}
-			numbers = append(numbers, input.Atoi(p))
-		}
-		c.numbers = numbers
-		c.op = problem[lastRow][0]
-		sum += c.calc()
+	lines, err := worksheet.Convert(rows, from, to)
+	if err != nil {
+		panic(err)
+	}
+	for _, l := range lines {
+		fmt.Println(l)
 	}
-	fmt.Printf("p1: %d\n", sum)
 }
 
-func p2() {
+func p1(breakdown bool) {
 	t := input.NewTXTFile("input.txt")
 	ctx := context.TODO()
 	var rows []string
@@ -84,55 +160,40 @@ func p2() {
 		rows = append(rows, line)
 		return nil
 	})
-	lastRow := len(rows) - 1
-	var indexLocs []int
-	for i, c := range rows[lastRow] {
-		if c != ' ' {
-			indexLocs = append(indexLocs, i)
-		}
+	results := solve(rows, worksheet.Human)
+	if breakdown {
+		printBreakdown(os.Stdout, results)
 	}
-	rowMap := make(map[int][]string)
-	for rindex, r := range rows {
-		if rindex == lastRow {
-			continue
-		}
-		var next int
-		var parts []string
-		for i := 1; i < len(indexLocs); i++ {
-			parts = append(parts, r[next:indexLocs[i]])
-			rowMap[i-1] = append(rowMap[i-1], r[next:indexLocs[i]])
-			next = indexLocs[i]
-		}
-		parts = append(parts, r[next:])
-		rowMap[len(parts)-1] = append(rowMap[len(parts)-1], parts[len(parts)-1])
-	}
-	var sum int
-	for i, r := range rowMap {
-		vr := make(map[int][]string)
-		for _, e := range r {
-			for i := 0; i < len(e); i++ {
-				vr[i] = append(vr[i], string(e[i]))
-			}
-		}
-		cal := calculator{
-			op: byte(rows[lastRow][indexLocs[i]]),
-		}
-		var numbers []int
-		for _, vs := range vr {
-			s := strings.Join(vs, "")
-			s = strings.ReplaceAll(s, " ", "")
-			if s != "" {
-				numbers = append(numbers, input.Atoi(s))
-			}
-		}
-		// fmt.Printf("number: %v,op: %v\n", numbers, string(rows[lastRow][indexLocs[i]]))
-		cal.numbers = numbers
-		sum += cal.calc()
+	fmt.Printf("p1: %d\n", total(results))
+}
+
+func p2(breakdown bool) {
+	t := input.NewTXTFile("input.txt")
+	ctx := context.TODO()
+	var rows []string
+	t.ReadByLine(ctx, func(line string) error {
+		rows = append(rows, line)
+		return nil
+	})
+	results := solve(rows, worksheet.Cephalopod)
+	if breakdown {
+		printBreakdown(os.Stdout, results)
 	}
-	fmt.Printf("p2: %d\n", sum)
+	fmt.Printf("p2: %d\n", total(results))
 }
 
 func main() {
-	p1()
-	p2()
+	breakdown := flag.Bool("breakdown", false, "print every problem with its numbers and result")
+	convertTo := flag.String("convert", "", `print the worksheet converted to "human" or "cephalopod" orientation`)
+	flag.Parse()
+	if *convertTo != "" {
+		to, err := worksheet.ParseOrientation(*convertTo)
+		if err != nil {
+			panic(err)
+		}
+		convert(to)
+		return
+	}
+	p1(*breakdown)
+	p2(*breakdown)
 }
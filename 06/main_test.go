@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/scbizu/aoc2025/06/worksheet"
+)
+
+// TestRender checks that rendering problems and parsing the worksheet back
+// gives the same operators and numbers in both orientations.
+func TestRender(t *testing.T) {
+	cs := []calculator{
+		{op: "*", numbers: []int{123, 45, 6}, column: 1},
+		{op: "+", numbers: []int{328, 64, 98}, column: 5},
+		{op: "-", numbers: []int{7, 1000}, column: 9},
+		{op: "max", numbers: []int{5}, column: 14},
+	}
+	for _, o := range []worksheet.Orientation{worksheet.Human, worksheet.Cephalopod} {
+		lines, err := render(cs, o)
+		if err != nil {
+			t.Fatalf("%s: %v", o, err)
+		}
+		problems, err := worksheet.Parse(lines)
+		if err != nil {
+			t.Fatalf("%s: %v\n%q", o, err, lines)
+		}
+		if len(problems) != len(cs) {
+			t.Fatalf("%s: %d problems, want %d\n%q", o, len(problems), len(cs), lines)
+		}
+		for i, p := range problems {
+			ns, err := p.Numbers(o)
+			if err != nil {
+				t.Fatalf("%s: %v", o, err)
+			}
+			if p.Op != cs[i].op || !slices.Equal(ns, cs[i].numbers) {
+				t.Errorf("%s problem %d: got %s %v, want %s %v", o, i, p.Op, ns, cs[i].op, cs[i].numbers)
+			}
+		}
+	}
+}
+
+func TestRenderInvalid(t *testing.T) {
+	if _, err := render([]calculator{{op: "+", column: 3}}, worksheet.Human); err == nil {
+		t.Error("render accepted a problem without numbers")
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/scbizu/aoc2025/internal/checked"
+)
+
+// operator folds the numbers of a problem from left to right, the first
+// number is the initial result.
+type operator struct {
+	// fold combines the result so far with the next number, ok is false when
+	// the step can not be done exactly in an int.
+	fold func(acc, n int) (int, bool)
+	// bigFold is the same step on math/big, it is also the one reporting
+	// errors such as a division by zero.
+	bigFold func(acc, n *big.Int) (*big.Int, error)
+}
+
+var operators = map[string]operator{}
+
+// maxPowBits caps the size of a "^" result, so a large exponent is an error
+// instead of gigabytes of digits.
+const maxPowBits = 1 << 24
+
+// registerOperator makes name usable in the operator row of a worksheet.
+func registerOperator(name string, op operator) {
+	operators[name] = op
+}
+
+func init() {
+	registerOperator("+", operator{
+		fold: checked.Add,
+		bigFold: func(acc, n *big.Int) (*big.Int, error) {
+			return acc.Add(acc, n), nil
+		},
+	})
+	registerOperator("-", operator{
+		fold: checked.Sub,
+		bigFold: func(acc, n *big.Int) (*big.Int, error) {
+			return acc.Sub(acc, n), nil
+		},
+	})
+	registerOperator("*", operator{
+		fold: checked.Mul,
+		bigFold: func(acc, n *big.Int) (*big.Int, error) {
+			return acc.Mul(acc, n), nil
+		},
+	})
+	// "/" truncates towards zero like Go's integer division.
+	registerOperator("/", operator{
+		fold: func(acc, n int) (int, bool) {
+			if n == 0 || (n == -1 && acc == math.MinInt) {
+				return 0, false
+			}
+			return acc / n, true
+		},
+		bigFold: func(acc, n *big.Int) (*big.Int, error) {
+			if n.Sign() == 0 {
+				return nil, errors.New("division by zero")
+			}
+			return acc.Quo(acc, n), nil
+		},
+	})
+	registerOperator("min", operator{
+		fold: func(acc, n int) (int, bool) {
+			return min(acc, n), true
+		},
+		bigFold: func(acc, n *big.Int) (*big.Int, error) {
+			if n.Cmp(acc) < 0 {
+				return acc.Set(n), nil
+			}
+			return acc, nil
+		},
+	})
+	registerOperator("max", operator{
+		fold: func(acc, n int) (int, bool) {
+			return max(acc, n), true
+		},
+		bigFold: func(acc, n *big.Int) (*big.Int, error) {
+			if n.Cmp(acc) > 0 {
+				return acc.Set(n), nil
+			}
+			return acc, nil
+		},
+	})
+	// "^" is a left fold as well, so 2 ^ 3 ^ 2 is (2^3)^2.
+	registerOperator("^", operator{
+		fold: func(acc, n int) (int, bool) {
+			switch {
+			case n < 0:
+				return 0, false
+			case n == 0 || acc == 1:
+				return 1, true
+			case acc == 0:
+				return 0, true
+			case acc == -1:
+				return 1 - 2*(n%2), true
+			}
+			// |acc| >= 2 overflows within 64 steps.
+			res := 1
+			for range n {
+				var ok bool
+				if res, ok = checked.Mul(res, acc); !ok {
+					return 0, false
+				}
+			}
+			return res, true
+		},
+		bigFold: func(acc, n *big.Int) (*big.Int, error) {
+			if n.Sign() < 0 {
+				return nil, errors.New("negative exponent")
+			}
+			// 0, 1 and -1 stay small whatever the exponent, anything else
+			// grows by about its bit length per step.
+			if acc.CmpAbs(big.NewInt(1)) > 0 {
+				bits := new(big.Int).Mul(big.NewInt(int64(acc.BitLen())), n)
+				if bits.Cmp(big.NewInt(maxPowBits)) > 0 {
+					return nil, fmt.Errorf("%s ^ %s has about %s bits, more than %d", acc, n, bits, maxPowBits)
+				}
+			}
+			return acc.Exp(acc, n, nil), nil
+		},
+	})
+}
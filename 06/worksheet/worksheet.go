@@ -0,0 +1,243 @@
+// Package worksheet parses the column-aligned math worksheets of day 6.
+//
+// A worksheet is a block of number rows with an operator row at the bottom.
+// Problems sit side by side and are separated by columns that are blank on
+// every row.
+package worksheet
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Orientation is the direction numbers are read in a problem.
+type Orientation int
+
+const (
+	// Human reads one number per row, top to bottom.
+	Human Orientation = iota
+	// Cephalopod reads one number per column, right to left, with the most
+	// significant digit at the top.
+	Cephalopod
+)
+
+// Problem is the cell block of one problem.
+type Problem struct {
+	// Column is the 0-based offset of the block in the worksheet lines.
+	Column int
+	// Rows are the number rows of the block, padded with spaces to the block
+	// width.
+	Rows []string
+	Op   string
+}
+
+// Parse splits the worksheet lines into problems, left to right. Lines of
+// different lengths are padded with spaces.
+func Parse(lines []string) ([]Problem, error) {
+	if len(lines) < 2 {
+		return nil, errors.New("worksheet needs number rows and an operator row")
+	}
+	var width int
+	for _, l := range lines {
+		width = max(width, len(l))
+	}
+	padded := make([]string, len(lines))
+	for i, l := range lines {
+		padded[i] = l + strings.Repeat(" ", width-len(l))
+	}
+	blank := func(col int) bool {
+		for _, l := range padded {
+			if l[col] != ' ' {
+				return false
+			}
+		}
+		return true
+	}
+
+	var problems []Problem
+	for col := 0; col < width; {
+		if blank(col) {
+			col++
+			continue
+		}
+		end := col
+		for end < width && !blank(end) {
+			end++
+		}
+		p := Problem{
+			Column: col,
+			Op:     strings.TrimSpace(padded[len(padded)-1][col:end]),
+		}
+		if p.Op == "" {
+			return nil, fmt.Errorf("column %d: missing operator", col+1)
+		}
+		for _, l := range padded[:len(padded)-1] {
+			p.Rows = append(p.Rows, l[col:end])
+		}
+		problems = append(problems, p)
+		col = end
+	}
+	return problems, nil
+}
+
+// Width is the number of columns of the block.
+func (p Problem) Width() int {
+	if len(p.Rows) == 0 {
+		return 0
+	}
+	return len(p.Rows[0])
+}
+
+// cells returns the digit strings of the block in reading order, blank
+// ones are skipped.
+func (p Problem) cells(o Orientation) []string {
+	var cells []string
+	switch o {
+	case Human:
+		cells = append(cells, p.Rows...)
+	case Cephalopod:
+		for col := p.Width() - 1; col >= 0; col-- {
+			var b strings.Builder
+			for _, r := range p.Rows {
+				b.WriteByte(r[col])
+			}
+			cells = append(cells, b.String())
+		}
+	}
+	var res []string
+	for _, c := range cells {
+		if c = strings.ReplaceAll(c, " ", ""); c != "" {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Numbers reads the numbers of the problem in the given orientation.
+func (p Problem) Numbers(o Orientation) ([]int, error) {
+	var ns []int
+	for _, c := range p.cells(o) {
+		n, err := strconv.Atoi(c)
+		if err != nil {
+			return nil, fmt.Errorf("column %d: invalid number %q", p.Column+1, c)
+		}
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
+
+func (o Orientation) String() string {
+	if o == Cephalopod {
+		return "cephalopod"
+	}
+	return "human"
+}
+
+// ParseOrientation is the inverse of Orientation.String.
+func ParseOrientation(s string) (Orientation, error) {
+	switch s {
+	case "human":
+		return Human, nil
+	case "cephalopod":
+		return Cephalopod, nil
+	}
+	return 0, fmt.Errorf("unknown orientation %q", s)
+}
+
+// FromNumbers lays out a problem so that reading it in orientation o gives
+// numbers back. Human problems put one right-aligned number per row,
+// cephalopod problems put the first number in the rightmost column.
+func FromNumbers(numbers []int, op string, o Orientation) (Problem, error) {
+	if len(numbers) == 0 {
+		return Problem{}, errors.New("problem without numbers")
+	}
+	if op == "" || strings.Contains(op, " ") {
+		return Problem{}, fmt.Errorf("invalid operator %q", op)
+	}
+	ns := make([]string, len(numbers))
+	var digits int
+	for i, n := range numbers {
+		ns[i] = strconv.Itoa(n)
+		digits = max(digits, len(ns[i]))
+	}
+
+	p := Problem{Op: op}
+	switch o {
+	case Human:
+		width := max(digits, len(op))
+		for _, n := range ns {
+			p.Rows = append(p.Rows, fmt.Sprintf("%*s", width, n))
+		}
+	case Cephalopod:
+		width := max(len(ns), len(op))
+		rows := make([][]byte, digits)
+		for r := range rows {
+			rows[r] = []byte(strings.Repeat(" ", width))
+		}
+		for i, n := range ns {
+			col := width - 1 - i
+			for r := range n {
+				rows[r][col] = n[r]
+			}
+		}
+		for _, r := range rows {
+			p.Rows = append(p.Rows, string(r))
+		}
+	}
+	return p, nil
+}
+
+// Format writes problems side by side, separated by one blank column, with
+// the operator row last. Problems with fewer rows are padded at the bottom.
+func Format(problems []Problem) []string {
+	var height int
+	for _, p := range problems {
+		height = max(height, len(p.Rows))
+	}
+	lines := make([]string, height+1)
+	for i, p := range problems {
+		width := max(p.Width(), len(p.Op))
+		for r := range height {
+			cell := strings.Repeat(" ", width)
+			if r < len(p.Rows) {
+				cell = fmt.Sprintf("%-*s", width, p.Rows[r])
+			}
+			if i > 0 {
+				lines[r] += " "
+			}
+			lines[r] += cell
+		}
+		if i > 0 {
+			lines[height] += " "
+		}
+		lines[height] += fmt.Sprintf("%-*s", width, p.Op)
+	}
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	return lines
+}
+
+// Convert rewrites a worksheet written in orientation from so that it reads
+// the same problems in orientation to.
+func Convert(lines []string, from, to Orientation) ([]string, error) {
+	problems, err := Parse(lines)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Problem, 0, len(problems))
+	for _, p := range problems {
+		ns, err := p.Numbers(from)
+		if err != nil {
+			return nil, err
+		}
+		np, err := FromNumbers(ns, p.Op, to)
+		if err != nil {
+			return nil, fmt.Errorf("column %d: %w", p.Column+1, err)
+		}
+		res = append(res, np)
+	}
+	return Format(res), nil
+}
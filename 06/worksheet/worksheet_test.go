@@ -0,0 +1,99 @@
+package worksheet
+
+import (
+	"slices"
+	"testing"
+)
+
+var example = []string{
+	"123 328  51 64 ",
+	" 45 64  387 23 ",
+	"  6 98  215 314",
+	"*   +   *   +  ",
+}
+
+func TestNumbers(t *testing.T) {
+	want := map[Orientation][][]int{
+		Human:      {{123, 45, 6}, {328, 64, 98}, {51, 387, 215}, {64, 23, 314}},
+		Cephalopod: {{356, 24, 1}, {8, 248, 369}, {175, 581, 32}, {4, 431, 623}},
+	}
+	problems, err := Parse(example)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for o, numbers := range want {
+		for i, p := range problems {
+			got, err := p.Numbers(o)
+			if err != nil {
+				t.Fatalf("%s problem %d: %v", o, i, err)
+			}
+			if !slices.Equal(got, numbers[i]) {
+				t.Errorf("%s problem %d: Numbers() = %v, want %v", o, i, got, numbers[i])
+			}
+		}
+	}
+}
+
+// TestRoundTrip checks Parse, Numbers, FromNumbers, Format and Parse again
+// give back the same problems in both orientations.
+func TestRoundTrip(t *testing.T) {
+	for _, o := range []Orientation{Human, Cephalopod} {
+		problems, err := Parse(example)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var numbers [][]int
+		var rebuilt []Problem
+		for _, p := range problems {
+			ns, err := p.Numbers(o)
+			if err != nil {
+				t.Fatalf("%s: %v", o, err)
+			}
+			np, err := FromNumbers(ns, p.Op, o)
+			if err != nil {
+				t.Fatalf("%s: %v", o, err)
+			}
+			numbers = append(numbers, ns)
+			rebuilt = append(rebuilt, np)
+		}
+		again, err := Parse(Format(rebuilt))
+		if err != nil {
+			t.Fatalf("%s: %v", o, err)
+		}
+		if len(again) != len(problems) {
+			t.Fatalf("%s: %d problems after the round trip, want %d", o, len(again), len(problems))
+		}
+		for i, p := range again {
+			ns, err := p.Numbers(o)
+			if err != nil {
+				t.Fatalf("%s: %v", o, err)
+			}
+			if p.Op != problems[i].Op || !slices.Equal(ns, numbers[i]) {
+				t.Errorf("%s problem %d: got %s %v, want %s %v", o, i, p.Op, ns, problems[i].Op, numbers[i])
+			}
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	ceph, err := Convert(example, Human, Cephalopod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := Convert(ceph, Cephalopod, Human)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := Parse(example)
+	got, err := Parse(back)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range got {
+		gn, _ := p.Numbers(Human)
+		wn, _ := want[i].Numbers(Human)
+		if p.Op != want[i].Op || !slices.Equal(gn, wn) {
+			t.Errorf("problem %d: got %s %v, want %s %v", i, p.Op, gn, want[i].Op, wn)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/magejiCoder/magejiAoc/grid"
+)
+
+var (
+	up    = grid.Vec{X: 0, Y: -1}
+	down  = grid.Vec{X: 0, Y: 1}
+	left  = grid.Vec{X: -1, Y: 0}
+	right = grid.Vec{X: 1, Y: 0}
+)
+
+// beam is a beam sitting at a cell and about to move one cell in dir.
+type beam struct {
+	at  grid.Vec
+	dir grid.Vec
+}
+
+// classic reports whether the manifold only holds the puzzle elements, in
+// which case every beam moves down and flow can be used.
+func (m *manifold) classic() bool {
+	for _, c := range m.cells.All() {
+		if c != '.' && c != 'S' && c != '^' {
+			return false
+		}
+	}
+	return true
+}
+
+// step moves b into the next cell and returns the beams leaving that cell.
+// exit is true if b leaves the manifold instead, fork if the cell is a
+// splitter that made b choose between two ways, one of which may be past the
+// edge and dropped.
+//
+//	^      splits into the two cells beside it, the beams keep their direction
+//	| -    split beams crossing them into their two ends, beams along them pass
+//	/ \    mirrors
+//	#      absorbs the beam
+func (m *manifold) step(b beam) (next []beam, exit bool, fork bool) {
+	n := b.at.Add(b.dir)
+	cell, ok := m.cells.Get(n)
+	if !ok {
+		return nil, true, false
+	}
+	switch cell {
+	case '#':
+		return nil, false, false
+	case '/':
+		return []beam{{at: n, dir: grid.Vec{X: -b.dir.Y, Y: -b.dir.X}}}, false, false
+	case '\\':
+		return []beam{{at: n, dir: grid.Vec{X: b.dir.Y, Y: b.dir.X}}}, false, false
+	case '|':
+		if b.dir.X == 0 {
+			break
+		}
+		return []beam{{at: n, dir: up}, {at: n, dir: down}}, false, true
+	case '-':
+		if b.dir.Y == 0 {
+			break
+		}
+		return []beam{{at: n, dir: left}, {at: n, dir: right}}, false, true
+	case '^':
+		// a side past the edge is dropped, as in the puzzle.
+		side := grid.Vec{X: b.dir.Y, Y: b.dir.X}
+		for _, s := range []grid.Vec{n.Add(grid.Vec{X: -side.X, Y: -side.Y}), n.Add(side)} {
+			if m.cells.In(s) {
+				next = append(next, beam{at: s, dir: b.dir})
+			}
+		}
+		return next, false, true
+	}
+	return []beam{{at: n, dir: b.dir}}, false, false
+}
+
+// simulation is what the beams of a manifold did.
+type simulation struct {
+	// energized holds every cell a beam passed through.
+	energized map[grid.Vec]struct{}
+	// exits are the beams that left the manifold, at their last cell.
+	exits []beam
+	// splits is the number of splitters that sent a beam two ways.
+	splits int
+}
+
+// simulate runs every beam from every source until it leaves, is absorbed
+// or repeats a state it has been in, so loops terminate. Splitters that split
+// a beam are marked in m.splitter.
+func (m *manifold) simulate() simulation {
+	sim := simulation{
+		energized: make(map[grid.Vec]struct{}),
+	}
+	seen := make(map[beam]struct{})
+	var queue []beam
+	for _, s := range m.sources {
+		queue = append(queue, beam{at: s, dir: down})
+	}
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[b]; ok {
+			continue
+		}
+		seen[b] = struct{}{}
+		sim.energized[b.at] = struct{}{}
+		next, exit, fork := m.step(b)
+		if exit {
+			sim.exits = append(sim.exits, b)
+		}
+		if n := b.at.Add(b.dir); fork && len(next) == 2 && !m.splitter[n] {
+			m.splitter[n] = true
+			sim.splits++
+		}
+		queue = append(queue, next...)
+	}
+	return sim
+}
+
+// order returns every beam state reachable from the sources, each before
+// the states it leads to. A state reached again while its own descendants
+// are still being walked is a loop, which would give infinitely many
+// timelines, so it is reported as an error.
+func (m *manifold) order() ([]beam, error) {
+	type frame struct {
+		b    beam
+		next []beam
+		i    int
+	}
+	const (
+		walking = iota + 1
+		done
+	)
+	state := make(map[beam]int)
+	var post []beam
+	push := func(stack []*frame, b beam) []*frame {
+		next, _, _ := m.step(b)
+		state[b] = walking
+		return append(stack, &frame{b: b, next: next})
+	}
+	for _, s := range m.sources {
+		start := beam{at: s, dir: down}
+		if state[start] != 0 {
+			continue
+		}
+		stack := push(nil, start)
+		for len(stack) > 0 {
+			f := stack[len(stack)-1]
+			if f.i < len(f.next) {
+				c := f.next[f.i]
+				f.i++
+				switch state[c] {
+				case walking:
+					return nil, fmt.Errorf("beam loops at (%d,%d)", c.at.X, c.at.Y)
+				case done:
+					continue
+				}
+				stack = push(stack, c)
+				continue
+			}
+			state[f.b] = done
+			post = append(post, f.b)
+			stack = stack[:len(stack)-1]
+		}
+	}
+	for i, j := 0, len(post)-1; i < j; i, j = i+1, j-1 {
+		post[i], post[j] = post[j], post[i]
+	}
+	return post, nil
+}
+
+// propagate is flow for any manifold: it pushes the weights from the
+// sources through the beam states in order and returns the weight of every
+// beam leaving the manifold.
+func propagate[T any, P number[T]](m *manifold, seed P, share func(P) P) (map[beam]P, error) {
+	states, err := m.order()
+	if err != nil {
+		return nil, err
+	}
+	weights := make(map[beam]P, len(states))
+	add := func(b beam, w P) {
+		cur, ok := weights[b]
+		if !ok {
+			cur = P(new(T))
+			weights[b] = cur
+		}
+		cur.Add(cur, w)
+	}
+	for _, s := range m.sources {
+		add(beam{at: s, dir: down}, seed)
+	}
+	exits := make(map[beam]P)
+	for _, b := range states {
+		w := weights[b]
+		next, exit, fork := m.step(b)
+		if exit {
+			exits[b] = w
+		}
+		if fork {
+			w = share(w)
+		}
+		for _, c := range next {
+			add(c, w)
+		}
+	}
+	return exits, nil
+}
+
+// timelines counts the timelines leaving the manifold on any side.
+func (m *manifold) timelines() (*big.Int, error) {
+	exits, err := propagate(m, big.NewInt(1), countShare)
+	if err != nil {
+		return nil, err
+	}
+	total := new(big.Int)
+	for _, w := range exits {
+		total.Add(total, w)
+	}
+	return total, nil
+}
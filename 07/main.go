@@ -2,154 +2,237 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"math/big"
+	"os"
 
 	"github.com/magejiCoder/magejiAoc/grid"
 	"github.com/magejiCoder/magejiAoc/input"
+	"github.com/scbizu/aoc2025/internal/dense"
 )
 
 type manifold struct {
-	startAt  grid.Vec
+	// sources are the 'S' cells, each emits a beam moving down.
+	sources  []grid.Vec
 	splitter map[grid.Vec]bool
 	maxY     int
 	maxX     int
 
-	pathFrom map[grid.Vec]int
+	cells *dense.Grid[byte]
 }
 
-func (m *manifold) move(
-	startAt grid.Vec,
-) {
-	next := startAt.Add(grid.Vec{
-		X: 0,
-		Y: 1,
-	})
-	if next.Y > m.maxY {
-		return
+func newManifold(lines []string) *manifold {
+	cells, err := dense.Parse(lines)
+	if err != nil {
+		panic(err)
+	}
+	m := &manifold{
+		splitter: make(map[grid.Vec]bool),
+		maxY:     cells.Height() - 1,
+		maxX:     cells.Width() - 1,
+		cells:    cells,
 	}
+	for v, c := range cells.All() {
+		switch c {
+		case 'S':
+			m.sources = append(m.sources, v)
+		case '^', '|', '-':
+			m.splitter[v] = false
+		}
+	}
+	return m
+}
 
-	visit, ok := m.splitter[next]
-	if ok && !visit {
-		var sp1, sp2 bool
-		if next.X-1 >= 0 {
-			m.move(next.Add(grid.Vec{X: -1, Y: 0}))
-			sp1 = true
+// number is the weight a beam carries: *big.Int to count timelines,
+// *big.Rat for probabilities.
+type number[T any] interface {
+	*T
+	Add(x, y *T) *T
+	SetInt64(x int64) *T
+	Sign() int
+}
+
+// countShare passes the full count to both sides of a splitter.
+func countShare(c *big.Int) *big.Int {
+	return c
+}
+
+// halfShare treats a splitter as a 50/50 choice.
+func halfShare(c *big.Rat) *big.Rat {
+	return new(big.Rat).Mul(c, big.NewRat(1, 2))
+}
+
+// flow walks a classic manifold row by row from the top source down,
+// carrying the weight of the beams in each column. Every source emits seed,
+// a splitter hands share(weight) to both sides and a side past the edge is
+// dropped. It returns the number of splitters that split a beam to both
+// sides, marking them in m.splitter, and the weight leaving the bottom row
+// per column.
+func flow[T any, P number[T]](m *manifold, seed P, share func(P) P) (int, []T) {
+	cur := make([]T, m.maxX+1)
+	next := make([]T, m.maxX+1)
+	if len(m.sources) == 0 {
+		return 0, cur
+	}
+	// emit adds the beams of the sources on row y.
+	emit := func(y int) {
+		for _, s := range m.sources {
+			if s.Y == y {
+				P(&cur[s.X]).Add(&cur[s.X], seed)
+			}
 		}
-		if next.X+1 <= m.maxX {
-			m.move(next.Add(grid.Vec{X: 1, Y: 0}))
-			sp2 = true
+	}
+	top := m.sources[0].Y
+	var splits int
+	for y := top + 1; y <= m.maxY; y++ {
+		emit(y - 1)
+		for x := range next {
+			P(&next[x]).SetInt64(0)
 		}
-		if sp1 && sp2 {
-			m.splitter[next] = true
+		for x := range cur {
+			c := P(&cur[x])
+			if c.Sign() == 0 {
+				continue
+			}
+			v := grid.Vec{X: x, Y: y}
+			if cell, _ := m.cells.Get(v); cell != '^' {
+				P(&next[x]).Add(&next[x], c)
+				continue
+			}
+			sc := share(c)
+			if x-1 >= 0 {
+				P(&next[x-1]).Add(&next[x-1], sc)
+			}
+			if x+1 <= m.maxX {
+				P(&next[x+1]).Add(&next[x+1], sc)
+			}
+			if x-1 >= 0 && x+1 <= m.maxX && !m.splitter[v] {
+				m.splitter[v] = true
+				splits++
+			}
 		}
+		cur, next = next, cur
 	}
-	if !ok {
-		m.move(next)
-	}
+	emit(m.maxY)
+	return splits, cur
 }
 
-func (m *manifold) move2(
-	startAt grid.Vec,
-) int {
-	next := startAt.Add(grid.Vec{
-		X: 0,
-		Y: 1,
-	})
-	if next.Y > m.maxY {
-		return 1
+// sweep counts the splits and the timelines leaving a classic manifold in a
+// single flow.
+func (m *manifold) sweep() (int, *big.Int) {
+	splits, exits := flow(m, big.NewInt(1), countShare)
+	total := new(big.Int)
+	for x := range exits {
+		total.Add(total, &exits[x])
 	}
+	return splits, total
+}
 
-	if v, ok := m.pathFrom[next]; ok {
-		return v
+// exitColumns returns the weight of the beams leaving the bottom row per
+// column, see flow and propagate.
+func exitColumns[T any, P number[T]](m *manifold, seed P, share func(P) P) ([]T, error) {
+	if m.classic() {
+		_, cols := flow(m, seed, share)
+		return cols, nil
 	}
-
-	var total int
-	_, ok := m.splitter[next]
-	if ok {
-		if next.X-1 >= 0 {
-			left := next.Add(grid.Vec{X: -1, Y: 0})
-			total += m.move2(left)
-		}
-		if next.X+1 <= m.maxX {
-			right := next.Add(grid.Vec{X: 1, Y: 0})
-			total += m.move2(right)
-		}
+	exits, err := propagate(m, seed, share)
+	if err != nil {
+		return nil, err
 	}
-	if !ok {
-		total += m.move2(next)
+	cols := make([]T, m.maxX+1)
+	for b, w := range exits {
+		if b.dir == down && b.at.Y == m.maxY {
+			P(&cols[b.at.X]).Add(&cols[b.at.X], w)
+		}
 	}
-	m.pathFrom[next] = total
-	return total
+	return cols, nil
 }
 
-func p1() {
+func readManifold() *manifold {
 	txt := input.NewTXTFile("input.txt")
 	ctx := context.TODO()
-	m := &manifold{
-		splitter: make(map[grid.Vec]bool),
-	}
-	var y int
-	var maxX int
+	var lines []string
 	txt.ReadByLine(ctx, func(line string) error {
-		maxX = len(line) - 1
-		for x, c := range line {
-			if c == 'S' {
-				m.startAt = grid.Vec{X: x, Y: y}
-			}
-			if c == '^' {
-				m.splitter[grid.Vec{
-					X: x,
-					Y: y,
-				}] = false
-			}
-		}
-		y++
+		lines = append(lines, line)
 		return nil
 	})
-	m.maxY = y - 1
-	m.maxX = maxX
-	m.move(m.startAt)
-	var splitTimes int
-	for _, visit := range m.splitter {
-		if visit {
-			splitTimes++
+	return newManifold(lines)
+}
+
+func main() {
+	exits := flag.Bool("exits", false, "print the number of timelines leaving each bottom column")
+	probability := flag.Bool("probability", false, "with -exits, print exact exit probabilities taking every splitter as a 50/50 choice")
+	render := flag.Bool("render", false, "print the manifold with the beam paths drawn on it")
+	pngPath := flag.String("png", "", "write the beam paths as a PNG to this path")
+	flag.Parse()
+	m := readManifold()
+	if *render || *pngPath != "" {
+		if err := drawBeams(m, *render, *pngPath); err != nil {
+			panic(err)
+		}
+		return
+	}
+	if *exits {
+		if err := printExits(m, *probability); err != nil {
+			panic(err)
 		}
+		return
+	}
+	if m.classic() {
+		splitTimes, total := m.sweep()
+		fmt.Printf("p1: %d\n", splitTimes)
+		fmt.Printf("p2: %d\n", total)
+		return
+	}
+	// mirrors and friends send beams in every direction, so fall back to
+	// the general simulator.
+	sim := m.simulate()
+	fmt.Printf("p1: %d\n", sim.splits)
+	total, err := m.timelines()
+	if err != nil {
+		panic(err)
 	}
-	fmt.Printf("p1: %d\n", splitTimes)
+	fmt.Printf("p2: %d\n", total)
 }
 
-func p2() {
-	txt := input.NewTXTFile("input.txt")
-	ctx := context.TODO()
-	m := &manifold{
-		splitter: make(map[grid.Vec]bool),
-		pathFrom: make(map[grid.Vec]int),
-	}
-	var y int
-	var maxX int
-	txt.ReadByLine(ctx, func(line string) error {
-		maxX = len(line) - 1
-		for x, c := range line {
-			if c == 'S' {
-				m.startAt = grid.Vec{X: x, Y: y}
-			}
-			if c == '^' {
-				m.splitter[grid.Vec{
-					X: x,
-					Y: y,
-				}] = false
+func printExits(m *manifold, probability bool) error {
+	if probability {
+		cols, err := exitColumns(m, big.NewRat(1, 1), halfShare)
+		if err != nil {
+			return err
+		}
+		for x := range cols {
+			if cols[x].Sign() != 0 {
+				fmt.Printf("%d: %s\n", x, cols[x].RatString())
 			}
 		}
-		y++
 		return nil
-	})
-	m.maxY = y - 1
-	m.maxX = maxX
-	total := m.move2(m.startAt)
-	fmt.Printf("p2: %d\n", total)
+	}
+	cols, err := exitColumns(m, big.NewInt(1), countShare)
+	if err != nil {
+		return err
+	}
+	for x := range cols {
+		if cols[x].Sign() != 0 {
+			fmt.Printf("%d: %s\n", x, cols[x].String())
+		}
+	}
+	return nil
 }
 
-func main() {
-	p1()
-	p2()
+func drawBeams(m *manifold, ascii bool, pngPath string) error {
+	sim := m.simulate()
+	if ascii {
+		fmt.Print(m.render(sim))
+	}
+	if pngPath == "" {
+		return nil
+	}
+	f, err := os.Create(pngPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.writePNG(f, sim)
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/magejiCoder/magejiAoc/grid"
+)
+
+// cellSize is the edge length in pixels of one manifold cell in the PNG.
+const cellSize = 4
+
+// overlay returns the manifold cell drawn at v after a simulation: beam
+// cells become '|', splitters become '*' if they split a beam and 'x'
+// otherwise, e.g. a '^' on the edge that only had one side.
+func (m *manifold) overlay(sim simulation, v grid.Vec) byte {
+	c, _ := m.cells.Get(v)
+	if active, ok := m.splitter[v]; ok {
+		if active {
+			return '*'
+		}
+		return 'x'
+	}
+	if _, ok := sim.energized[v]; ok && c == '.' {
+		return '|'
+	}
+	return c
+}
+
+// render writes the manifold with the beam paths of sim on top of it.
+func (m *manifold) render(sim simulation) string {
+	var b strings.Builder
+	for y := 0; y <= m.maxY; y++ {
+		for x := 0; x <= m.maxX; x++ {
+			b.WriteByte(m.overlay(sim, grid.Vec{X: x, Y: y}))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+var overlayColors = map[byte]color.Color{
+	'.':  color.RGBA{R: 0x10, G: 0x10, B: 0x18, A: 0xff},
+	'|':  color.RGBA{R: 0xff, G: 0xd0, B: 0x30, A: 0xff},
+	'x':  color.RGBA{R: 0xe0, G: 0x30, B: 0x30, A: 0xff},
+	'*':  color.RGBA{R: 0x30, G: 0xd0, B: 0x60, A: 0xff},
+	'S':  color.RGBA{R: 0x30, G: 0x80, B: 0xff, A: 0xff},
+	'#':  color.RGBA{R: 0x60, G: 0x60, B: 0x60, A: 0xff},
+	'/':  color.RGBA{R: 0xa0, G: 0xa0, B: 0xa0, A: 0xff},
+	'\\': color.RGBA{R: 0xa0, G: 0xa0, B: 0xa0, A: 0xff},
+}
+
+// writePNG draws the same overlay as render, one cellSize square per cell.
+func (m *manifold) writePNG(w io.Writer, sim simulation) error {
+	img := image.NewRGBA(image.Rect(0, 0, (m.maxX+1)*cellSize, (m.maxY+1)*cellSize))
+	for v := range m.cells.All() {
+		c := m.overlay(sim, v)
+		col, ok := overlayColors[c]
+		if !ok {
+			col = overlayColors['.']
+		}
+		for dy := 0; dy < cellSize; dy++ {
+			for dx := 0; dx < cellSize; dx++ {
+				img.Set(v.X*cellSize+dx, v.Y*cellSize+dy, col)
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
@@ -2,138 +2,151 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"math"
+	"os"
 	"sort"
 	"strings"
 
 	"github.com/magejiCoder/magejiAoc/grid"
 	"github.com/magejiCoder/magejiAoc/input"
-	"github.com/magejiCoder/magejiAoc/set"
+	"github.com/scbizu/aoc2025/internal/unionfind"
 )
 
 type junctionBox struct {
-	all []grid.Vector3D[int]
+	all    []grid.Vector3D[int]
+	metric metric
 }
 
-func euclideanDistance(v1, v2 grid.Vector3D[int]) float64 {
-	return math.Sqrt(math.Pow(float64(v1.X-v2.X), 2) + math.Pow(float64(v1.Y-v2.Y), 2) + math.Pow(float64(v1.Z-v2.Z), 2))
+// conn is a possible connection between the boxes all[i] and all[j], dist
+// is the distance between them as measured by the metric.
+type conn struct {
+	i, j int
+	dist int64
 }
 
-type conn struct {
-	j1, j2 grid.Vector3D[int]
-	dist   float64
+// less orders connections by length, and connections of equal length by
+// their boxes, so the order never depends on how the pairs were found.
+func (c conn) less(o conn) bool {
+	if c.dist != o.dist {
+		return c.dist < o.dist
+	}
+	c1, c2 := min(c.i, c.j), max(c.i, c.j)
+	o1, o2 := min(o.i, o.j), max(o.i, o.j)
+	if c1 != o1 {
+		return c1 < o1
+	}
+	return c2 < o2
 }
 
-func (jb junctionBox) connect(times int) int {
-	all := jb.all
-	var conns []conn
-	for i := range all {
-		for j := i + 1; j < len(all); j++ {
-			dist := euclideanDistance(all[i], all[j])
-			conns = append(conns, conn{
-				j1:   all[i],
-				j2:   all[j],
-				dist: dist,
-			})
-		}
+// circuitStats describes the circuits after the first connections were
+// made.
+type circuitStats struct {
+	// connections is the number of connections made.
+	connections int
+	// circuits is the number of circuits, counting lone boxes.
+	circuits int
+	// sizes are the circuit sizes, largest first.
+	sizes []int
+	// largest holds the boxes of the largest circuit, the one with the
+	// lowest box among equally large ones.
+	largest []grid.Vector3D[int]
+	// joined is the number of connections after which every box was in one
+	// circuit, last is the connection that closed it. joined is -1 if that
+	// never happens, which is only the case without boxes.
+	joined int
+	last   conn
+}
+
+// product multiplies the sizes of the n largest circuits, or of all of them
+// if there are fewer.
+func (st circuitStats) product(n int) int {
+	p := 1
+	for _, size := range st.sizes[:min(n, len(st.sizes))] {
+		p *= size
 	}
-	sort.Slice(conns, func(i, j int) bool {
-		return conns[i].dist < conns[j].dist
-	})
-	// fmt.Printf("conn: %v\n", conns)
-	jbs := []*set.Set[grid.Vector3D[int]]{}
-	for i, conn := range conns[:times] {
-		fmt.Printf("time: %d\n", i)
-		var merge []*set.Set[grid.Vector3D[int]]
-		for index, b := range jbs {
-			if b.Has(conn.j1) || b.Has(conn.j2) {
-				b.Add(conn.j1)
-				b.Add(conn.j2)
-				merge = append(merge, b)
-				jbs = append(jbs[:index], jbs[index+1:]...)
+	return p
+}
+
+// analyse makes the connections shortest first. It describes the circuits
+// after the first times of them and carries on until all boxes are joined.
+func (jb junctionBox) analyse(times int) circuitStats {
+	circuits := unionfind.New(len(jb.all))
+	st := circuitStats{joined: -1}
+	if len(jb.all) == 1 {
+		st.joined = 0
+	}
+	var made int
+	taken := false
+	snapshot := func() {
+		taken = true
+		st.connections = made
+		st.circuits = circuits.Components()
+		st.sizes = circuits.Sizes()
+		sort.Slice(st.sizes, func(i, j int) bool {
+			return st.sizes[i] > st.sizes[j]
+		})
+		if len(jb.all) == 0 {
+			return
+		}
+		root := circuits.Find(0)
+		for i := range jb.all {
+			if circuits.Size(i) > circuits.Size(root) {
+				root = circuits.Find(i)
 			}
 		}
-		if len(merge) == 0 {
-			jbs = append(jbs, set.New(conn.j1, conn.j2))
-		} else {
-			jbs = append(jbs, set.Union(merge...))
+		for i, b := range jb.all {
+			if circuits.Find(i) == root {
+				st.largest = append(st.largest, b)
+			}
 		}
-		// fmt.Printf("jbs: %v\n", jbs)
 	}
-	var bcons []int
-	for _, b := range jbs {
-		bcons = append(bcons, b.Size())
+	if times <= 0 {
+		snapshot()
 	}
-	sort.Slice(bcons, func(i, j int) bool {
-		return bcons[i] > bcons[j]
-	})
-	return bcons[0] * bcons[1] * bcons[2]
-}
-
-func (jb junctionBox) combine() int {
-	all := jb.all
-	var conns []conn
-	for i := range all {
-		for j := i + 1; j < len(all); j++ {
-			dist := euclideanDistance(all[i], all[j])
-			conns = append(conns, conn{
-				j1:   all[i],
-				j2:   all[j],
-				dist: dist,
-			})
+	for c := range jb.conns() {
+		if taken && st.joined >= 0 {
+			break
 		}
-	}
-	sort.Slice(conns, func(i, j int) bool {
-		return conns[i].dist < conns[j].dist
-	})
-	// fmt.Printf("conn: %v\n", conns)
-	jbs := []*set.Set[grid.Vector3D[int]]{}
-	for _, conn := range conns {
-		// fmt.Printf("time: %d\n", i)
-		// fmt.Printf("conn: %v,%v\n", conn.j1, conn.j2)
-		s := set.New(
-			conn.j1, conn.j2,
-		)
-		var njb []*set.Set[grid.Vector3D[int]]
-		for _, b := range jbs {
-			if b.HasAny(conn.j1, conn.j2) {
-				s = set.Union(s, b)
-			} else {
-				njb = append(njb, b)
-			}
-			// fmt.Printf("len: %d,index: %d\n", len(jbs), index)
+		made++
+		if circuits.Union(c.i, c.j) && circuits.Components() == 1 {
+			st.joined, st.last = made, c
 		}
-		njb = append(njb, s)
-		jbs = njb
-		if len(jbs) == 1 && jbs[0].Size() == len(jb.all) {
-			// fmt.Printf("jbs[%d:%d]: %v\n", jbs[0].Size(), len(jb.all), jbs)
-			return conn.j1.X * conn.j2.X
+		if made == times {
+			snapshot()
 		}
 	}
-	panic("can not reach")
+	// there were fewer pairs than connections to make.
+	if !taken {
+		snapshot()
+	}
+	return st
 }
 
-func p1() {
-	txt := input.NewTXTFile("input.txt")
-	ctx := context.TODO()
-	bx := junctionBox{}
-	txt.ReadByLine(ctx, func(line string) error {
-		parts := strings.Split(line, ",")
-		bx.all = append(bx.all, grid.Vector3D[int]{
-			X: input.Atoi(parts[0]),
-			Y: input.Atoi(parts[1]),
-			Z: input.Atoi(parts[2]),
-		})
-		return nil
-	})
-	fmt.Printf("p1: %d\n", bx.connect(10))
+func printStats(jb junctionBox, st circuitStats) {
+	fmt.Printf("connections: %d\n", st.connections)
+	fmt.Printf("circuits: %d\n", st.circuits)
+	fmt.Printf("sizes: %v\n", st.sizes)
+	fmt.Printf("largest: %d boxes\n", len(st.largest))
+	for _, b := range st.largest {
+		fmt.Printf("  %d,%d,%d\n", b.X, b.Y, b.Z)
+	}
+	if st.joined < 0 {
+		fmt.Println("joined: never")
+		return
+	}
+	fmt.Printf("joined: after %d connections", st.joined)
+	if st.joined > 0 {
+		a, b := jb.all[st.last.i], jb.all[st.last.j]
+		fmt.Printf(", last %d,%d,%d - %d,%d,%d", a.X, a.Y, a.Z, b.X, b.Y, b.Z)
+	}
+	fmt.Println()
 }
 
-func p2() {
+func readBoxes(m metric) (junctionBox, error) {
 	txt := input.NewTXTFile("input.txt")
 	ctx := context.TODO()
-	bx := junctionBox{}
+	bx := junctionBox{metric: m}
 	txt.ReadByLine(ctx, func(line string) error {
 		parts := strings.Split(line, ",")
 		bx.all = append(bx.all, grid.Vector3D[int]{
@@ -143,10 +156,52 @@ func p2() {
 		})
 		return nil
 	})
-	fmt.Printf("p2: %d\n", bx.combine())
+	if err := checkSpan(bx.all); err != nil {
+		return junctionBox{}, err
+	}
+	return bx, nil
 }
 
 func main() {
-	p1()
-	p2()
+	name := flag.String("metric", "euclidean", `"euclidean", "manhattan" or "chebyshev"`)
+	connections := flag.Int("connections", 1000, "number of shortest connections for part 1, the example uses 10")
+	stats := flag.Bool("stats", false, "print the circuits after the connections instead of the answers")
+	mst := flag.String("mst", "", `write the minimum spanning tree as "dot" or "json" instead of the answers, with the distance of every edge`)
+	components := flag.Int("components", 1, "with -mst, stop once this many circuits are left")
+	flag.Parse()
+	m, ok := metrics[*name]
+	if !ok {
+		panic(fmt.Sprintf("unknown metric %q", *name))
+	}
+	bx, err := readBoxes(m)
+	if err != nil {
+		panic(err)
+	}
+	if *mst != "" {
+		if err := exportTree(bx, *mst, *components); err != nil {
+			panic(err)
+		}
+		return
+	}
+	st := bx.analyse(*connections)
+	if *stats {
+		printStats(bx, st)
+		return
+	}
+	fmt.Printf("p1: %d\n", st.product(3))
+	if st.joined <= 0 {
+		panic("no connection joins the boxes into one circuit")
+	}
+	fmt.Printf("p2: %d\n", bx.all[st.last.i].X*bx.all[st.last.j].X)
+}
+
+func exportTree(jb junctionBox, format string, components int) error {
+	tree := jb.spanningTree(components)
+	switch format {
+	case "dot":
+		return writeDOT(os.Stdout, jb, tree)
+	case "json":
+		return writeJSON(os.Stdout, jb, tree)
+	}
+	return fmt.Errorf("unknown tree format %q", format)
 }
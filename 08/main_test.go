@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/magejiCoder/magejiAoc/grid"
+)
+
+var example = []grid.Vector3D[int]{
+	{X: 162, Y: 817, Z: 812}, {X: 57, Y: 618, Z: 57}, {X: 906, Y: 360, Z: 560},
+	{X: 592, Y: 479, Z: 940}, {X: 352, Y: 342, Z: 300}, {X: 466, Y: 668, Z: 158},
+	{X: 542, Y: 29, Z: 236}, {X: 431, Y: 825, Z: 988}, {X: 739, Y: 650, Z: 466},
+	{X: 52, Y: 470, Z: 668}, {X: 216, Y: 146, Z: 977}, {X: 819, Y: 987, Z: 18},
+	{X: 117, Y: 168, Z: 530}, {X: 805, Y: 96, Z: 715}, {X: 346, Y: 949, Z: 466},
+	{X: 970, Y: 615, Z: 88}, {X: 941, Y: 993, Z: 340}, {X: 862, Y: 61, Z: 35},
+	{X: 984, Y: 92, Z: 344}, {X: 425, Y: 690, Z: 689},
+}
+
+func TestExample(t *testing.T) {
+	jb := junctionBox{all: example, metric: metrics["euclidean"]}
+	st := jb.analyse(10)
+	if got := st.product(3); got != 40 {
+		t.Errorf("p1 = %d, want 40", got)
+	}
+	if got := jb.all[st.last.i].X * jb.all[st.last.j].X; got != 25272 {
+		t.Errorf("p2 = %d, want 25272", got)
+	}
+}
+
+// TestConns checks the lazily merged connections against every pair sorted
+// up front, for every metric and for boxes packed tightly enough that many
+// distances tie. A metric without axisBound is included too, it walks the
+// whole tree for every batch.
+func TestConns(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	all := map[string]metric{
+		"custom": {distance: func(a, b grid.Vector3D[int]) int64 {
+			return 3*abs(a.X-b.X) + abs(a.Z-b.Z)
+		}},
+	}
+	for name, m := range metrics {
+		all[name] = m
+	}
+	for name, m := range all {
+		for it := range 200 {
+			span := 1000
+			if it%2 == 1 {
+				span = 1 + r.Intn(5)
+			}
+			jb := junctionBox{metric: m}
+			for range r.Intn(60) {
+				jb.all = append(jb.all, grid.Vector3D[int]{X: r.Intn(span), Y: r.Intn(span), Z: r.Intn(span)})
+			}
+			var want []conn
+			for i := range jb.all {
+				for j := i + 1; j < len(jb.all); j++ {
+					want = append(want, conn{i: i, j: j, dist: m.distance(jb.all[i], jb.all[j])})
+				}
+			}
+			sort.Slice(want, func(a, b int) bool {
+				return want[a].less(want[b])
+			})
+			var got []conn
+			for c := range jb.conns() {
+				got = append(got, c)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("%s: %d connections, want %d", name, len(got), len(want))
+			}
+			for k := range got {
+				if got[k] != want[k] {
+					t.Fatalf("%s: connection %d is %v, want %v", name, k, got[k], want[k])
+				}
+			}
+		}
+	}
+}
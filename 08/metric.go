@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/magejiCoder/magejiAoc/grid"
+	"github.com/scbizu/aoc2025/internal/checked"
+)
+
+// metric measures how far apart two boxes are. Only the order of the
+// distances matters, so a metric may return any increasing function of the
+// distance, e.g. its square, as long as it is exact.
+type metric struct {
+	distance func(a, b grid.Vector3D[int]) int64
+	// axisBound is a lower bound of distance for two boxes d apart along a
+	// single axis. It lets the k-d tree skip far subtrees, a metric without
+	// one is still correct but measures every box against every other.
+	axisBound func(d int) int64
+	// length turns a value of distance back into the distance itself, e.g.
+	// a square root. It is nil for metrics returning the distance already.
+	length func(d int64) float64
+}
+
+// lengthOf is the actual distance for the value d of m.distance.
+func (m metric) lengthOf(d int64) float64 {
+	if m.length == nil {
+		return float64(d)
+	}
+	return m.length(d)
+}
+
+var metrics = map[string]metric{}
+
+// registerMetric makes name usable with -metric. Any user-supplied distance
+// function can be registered this way.
+func registerMetric(name string, m metric) {
+	metrics[name] = m
+}
+
+func init() {
+	registerMetric("euclidean", metric{
+		distance: squaredDistance,
+		axisBound: func(d int) int64 {
+			return int64(d) * int64(d)
+		},
+		length: func(d int64) float64 {
+			return math.Sqrt(float64(d))
+		},
+	})
+	// a wire routed along the grid, as used for the wiring plans.
+	registerMetric("manhattan", metric{
+		distance: func(a, b grid.Vector3D[int]) int64 {
+			return abs(a.X-b.X) + abs(a.Y-b.Y) + abs(a.Z-b.Z)
+		},
+		axisBound: abs,
+	})
+	registerMetric("chebyshev", metric{
+		distance: func(a, b grid.Vector3D[int]) int64 {
+			return max(abs(a.X-b.X), abs(a.Y-b.Y), abs(a.Z-b.Z))
+		},
+		axisBound: abs,
+	})
+}
+
+// squaredDistance is the squared Euclidean distance, which orders pairs the
+// same way as the distance itself but stays exact. checkSpan makes sure it
+// fits an int64 for every pair of boxes.
+func squaredDistance(v1, v2 grid.Vector3D[int]) int64 {
+	dx, dy, dz := int64(v1.X-v2.X), int64(v1.Y-v2.Y), int64(v1.Z-v2.Z)
+	return dx*dx + dy*dy + dz*dz
+}
+
+// checkSpan rejects boxes spread so far that the squared distance of the
+// corners of their bounding box overflows. No pair is further apart than
+// those corners, and the built-in metrics never exceed the squared distance,
+// so they are exact for every pair once this passes.
+func checkSpan(all []grid.Vector3D[int]) error {
+	if len(all) == 0 {
+		return nil
+	}
+	lo, hi := all[0], all[0]
+	for _, b := range all[1:] {
+		lo.X, hi.X = min(lo.X, b.X), max(hi.X, b.X)
+		lo.Y, hi.Y = min(lo.Y, b.Y), max(hi.Y, b.Y)
+		lo.Z, hi.Z = min(lo.Z, b.Z), max(hi.Z, b.Z)
+	}
+	var sum int
+	for _, span := range [][2]int{{lo.X, hi.X}, {lo.Y, hi.Y}, {lo.Z, hi.Z}} {
+		d, ok := checked.Sub(span[1], span[0])
+		if ok {
+			d, ok = checked.Mul(d, d)
+		}
+		if ok {
+			sum, ok = checked.Add(sum, d)
+		}
+		if !ok {
+			return fmt.Errorf("boxes from %d,%d,%d to %d,%d,%d are too far apart for exact distances", lo.X, lo.Y, lo.Z, hi.X, hi.Y, hi.Z)
+		}
+	}
+	return nil
+}
+
+func abs(d int) int64 {
+	if d < 0 {
+		return -int64(d)
+	}
+	return int64(d)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/scbizu/aoc2025/internal/unionfind"
+)
+
+// spanningTree runs Kruskal over the connections, shortest first, and
+// returns the ones joining two circuits until only components circuits are
+// left. With components 1 that is the minimum spanning tree, with more it is
+// a single-linkage clustering of the boxes.
+func (jb junctionBox) spanningTree(components int) []conn {
+	circuits := unionfind.New(len(jb.all))
+	var tree []conn
+	for c := range jb.conns() {
+		if circuits.Components() <= max(components, 1) {
+			break
+		}
+		if circuits.Union(c.i, c.j) {
+			tree = append(tree, c)
+		}
+	}
+	return tree
+}
+
+// writeDOT writes the boxes and the tree as an undirected Graphviz graph,
+// every edge labelled with its actual distance.
+func writeDOT(w io.Writer, jb junctionBox, tree []conn) error {
+	if _, err := fmt.Fprintln(w, "graph circuits {"); err != nil {
+		return err
+	}
+	for i, b := range jb.all {
+		if _, err := fmt.Fprintf(w, "\t%d [label=\"%d,%d,%d\"];\n", i, b.X, b.Y, b.Z); err != nil {
+			return err
+		}
+	}
+	for _, c := range tree {
+		if _, err := fmt.Fprintf(w, "\t%d -- %d [label=\"%.6g\"];\n", c.i, c.j, jb.metric.lengthOf(c.dist)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+type jsonTree struct {
+	Boxes [][3]int   `json:"boxes"`
+	Edges []jsonEdge `json:"edges"`
+}
+
+// jsonEdge joins two boxes, by their index in boxes. Distance is the actual
+// distance, Weight the exact value the metric orders edges by, e.g. the
+// squared distance for euclidean.
+type jsonEdge struct {
+	From     int     `json:"from"`
+	To       int     `json:"to"`
+	Distance float64 `json:"distance"`
+	Weight   int64   `json:"weight"`
+}
+
+// writeJSON writes the boxes and the tree as JSON.
+func writeJSON(w io.Writer, jb junctionBox, tree []conn) error {
+	out := jsonTree{
+		Boxes: make([][3]int, 0, len(jb.all)),
+		Edges: make([]jsonEdge, 0, len(tree)),
+	}
+	for _, b := range jb.all {
+		out.Boxes = append(out.Boxes, [3]int{b.X, b.Y, b.Z})
+	}
+	for _, c := range tree {
+		out.Edges = append(out.Edges, jsonEdge{
+			From:     c.i,
+			To:       c.j,
+			Distance: jb.metric.lengthOf(c.dist),
+			Weight:   c.dist,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"container/heap"
+	"iter"
+	"sort"
+
+	"github.com/magejiCoder/magejiAoc/grid"
+)
+
+// neighbourBatch is how many neighbours of a box are looked up at once.
+const neighbourBatch = 8
+
+// neighbour is the box all[j] at distance dist from the box being looked
+// at. They order by distance, then by index, so every box sees the others in
+// one fixed order even when distances tie.
+type neighbour struct {
+	j    int
+	dist int64
+}
+
+func (n neighbour) less(o neighbour) bool {
+	if n.dist != o.dist {
+		return n.dist < o.dist
+	}
+	return n.j < o.j
+}
+
+// kdTree is a 3-d tree over the boxes, used to find the nearest boxes of a
+// box without measuring all of them.
+type kdTree struct {
+	all    []grid.Vector3D[int]
+	metric metric
+	nodes  []kdNode
+	root   int
+}
+
+type kdNode struct {
+	// box is the index of the box the node splits at.
+	box         int
+	axis        int
+	left, right int
+}
+
+func axisOf(v grid.Vector3D[int], axis int) int {
+	switch axis {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	}
+	return v.Z
+}
+
+func newKDTree(all []grid.Vector3D[int], m metric) *kdTree {
+	t := &kdTree{all: all, metric: m}
+	boxes := make([]int, len(all))
+	for i := range boxes {
+		boxes[i] = i
+	}
+	t.root = t.build(boxes, 0)
+	return t
+}
+
+// build splits boxes at the median along axis and returns the node index,
+// -1 for no boxes.
+func (t *kdTree) build(boxes []int, axis int) int {
+	if len(boxes) == 0 {
+		return -1
+	}
+	sort.Slice(boxes, func(i, j int) bool {
+		return axisOf(t.all[boxes[i]], axis) < axisOf(t.all[boxes[j]], axis)
+	})
+	mid := len(boxes) / 2
+	n := len(t.nodes)
+	t.nodes = append(t.nodes, kdNode{box: boxes[mid], axis: axis})
+	next := (axis + 1) % 3
+	left := t.build(boxes[:mid], next)
+	right := t.build(boxes[mid+1:], next)
+	t.nodes[n].left, t.nodes[n].right = left, right
+	return n
+}
+
+// nearest returns up to k neighbours of the box q, nearest first, skipping
+// q itself and every neighbour up to and including after.
+func (t *kdTree) nearest(q int, after neighbour, k int) []neighbour {
+	var best []neighbour
+	p := t.all[q]
+	var walk func(n int)
+	walk = func(n int) {
+		if n < 0 {
+			return
+		}
+		node := t.nodes[n]
+		if node.box != q {
+			c := neighbour{j: node.box, dist: t.metric.distance(p, t.all[node.box])}
+			if after.less(c) && (len(best) < k || c.less(best[len(best)-1])) {
+				i := sort.Search(len(best), func(i int) bool {
+					return c.less(best[i])
+				})
+				best = append(best, neighbour{})
+				copy(best[i+1:], best[i:])
+				best[i] = c
+				if len(best) > k {
+					best = best[:k]
+				}
+			}
+		}
+		d := axisOf(p, node.axis) - axisOf(t.all[node.box], node.axis)
+		near, far := node.left, node.right
+		if d > 0 {
+			near, far = far, near
+		}
+		walk(near)
+		// the far side is at least d away along the axis alone.
+		if len(best) < k || t.metric.axisBound == nil || t.metric.axisBound(d) <= best[len(best)-1].dist {
+			walk(far)
+		}
+	}
+	walk(t.root)
+	return best
+}
+
+// frontier holds, for every box, the next connection it has not handed out
+// yet, shortest on top.
+type frontier []conn
+
+func (f frontier) Len() int           { return len(f) }
+func (f frontier) Less(a, b int) bool { return f[a].less(f[b]) }
+func (f frontier) Swap(a, b int)      { f[a], f[b] = f[b], f[a] }
+func (f *frontier) Push(x any)        { *f = append(*f, x.(conn)) }
+func (f *frontier) Pop() any {
+	old := *f
+	c := old[len(old)-1]
+	*f = old[:len(old)-1]
+	return c
+}
+
+// conns yields every pair of boxes, shortest first, without building the
+// pairs up front. Every box walks its neighbours nearest first through the
+// k-d tree, a batch at a time, and a heap merges those walks. A pair shows up
+// in the walks of both its boxes, it is only yielded from the lower index.
+func (jb junctionBox) conns() iter.Seq[conn] {
+	return func(yield func(conn) bool) {
+		tree := newKDTree(jb.all, jb.metric)
+		pending := make([][]neighbour, len(jb.all))
+		f := &frontier{}
+		// advance pushes the next connection of box i, if any.
+		advance := func(i int, after neighbour) {
+			if len(pending[i]) == 0 {
+				pending[i] = tree.nearest(i, after, neighbourBatch)
+				if len(pending[i]) == 0 {
+					return
+				}
+			}
+			n := pending[i][0]
+			pending[i] = pending[i][1:]
+			heap.Push(f, conn{i: i, j: n.j, dist: n.dist})
+		}
+		for i := range jb.all {
+			advance(i, neighbour{j: -1, dist: -1})
+		}
+		for f.Len() > 0 {
+			c := heap.Pop(f).(conn)
+			if c.i < c.j && !yield(c) {
+				return
+			}
+			advance(c.i, neighbour{j: c.j, dist: c.dist})
+		}
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/magejiCoder/magejiAoc/grid"
 	"github.com/magejiCoder/magejiAoc/input"
+	"github.com/scbizu/aoc2025/internal/dense"
 )
 
 type Farm struct {
@@ -16,12 +16,12 @@ type Farm struct {
 
 type present struct {
 	// shape 为 3*3 的矩阵
-	shape grid.VecMatrix[byte]
+	shape *dense.Grid[byte]
 }
 
 type region struct {
 	// ava 表示可用区域
-	ava          grid.VecMatrix[struct{}]
+	ava          *dense.Grid[bool]
 	needPresents []acquiredPresent
 }
 
@@ -38,10 +38,10 @@ func (r *region) fill(presents []present) bool {
 	// 计算所有 presents 的格点总数
 	totalArea := 0
 	for _, p := range presents {
-		totalArea += len(p.shape)
+		totalArea += p.shape.Count(func(c byte) bool { return c == '#' })
 	}
 
-	return totalArea <= len(r.ava)
+	return totalArea <= r.ava.Len()
 }
 
 func (f Farm) validRegions() int {
@@ -67,35 +67,26 @@ func p1() {
 		// fist N blocks are presents (shape)
 		var presents []present
 		for i := 0; i < len(block)-1; i++ {
-			p := present{
-				shape: grid.NewVecMatrix[byte](),
-			}
 			// per line
 			parts := strings.Split(block[i], "\n")
 			// ignore presents index string
-			for li, line := range parts[1:] {
-				for co, col := range line {
-					if col == '#' {
-						p.shape.Add(grid.Vec{X: li, Y: co}, byte(col))
-					}
-				}
+			shape, err := dense.Parse(parts[1:])
+			if err != nil {
+				panic(err)
 			}
-			presents = append(presents, p)
+			presents = append(presents, present{
+				shape: shape,
+			})
 		}
 		// last 1 block are region
 		parts := strings.Split(block[len(block)-1], "\n")
 		var regions []region
 		for _, line := range parts {
-			r := region{
-				ava: grid.NewVecMatrix[struct{}](),
-			}
 			lps := strings.Split(line, ":")
 			// part 0 is the grid
 			gridParts := strings.Split(lps[0], "x")
-			for i := 0; i < input.Atoi(gridParts[0]); i++ {
-				for j := 0; j < input.Atoi(gridParts[1]); j++ {
-					r.ava.Add(grid.Vec{X: i, Y: j}, struct{}{})
-				}
+			r := region{
+				ava: dense.New[bool](input.Atoi(gridParts[0]), input.Atoi(gridParts[1])),
 			}
 
 			// part 1 is the needed presents
@@ -0,0 +1,29 @@
+// Package checked does int arithmetic that reports overflow instead of
+// wrapping around, so callers can fall back to math/big.
+package checked
+
+import "math"
+
+// Add returns a+b, ok is false if the sum overflows.
+func Add(a, b int) (int, bool) {
+	c := a + b
+	return c, (c > a) == (b > 0)
+}
+
+// Sub returns a-b, ok is false if the difference overflows.
+func Sub(a, b int) (int, bool) {
+	c := a - b
+	return c, (c < a) == (b > 0)
+}
+
+// Mul returns a*b, ok is false if the product overflows.
+func Mul(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	if (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return 0, false
+	}
+	c := a * b
+	return c, c/b == a
+}
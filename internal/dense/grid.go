@@ -0,0 +1,120 @@
+// Package dense provides a rectangular grid stored in a flat slice, for the
+// puzzles whose input is a fully populated rectangle where a
+// grid.VecMatrix spends most of its time hashing.
+package dense
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/magejiCoder/magejiAoc/grid"
+)
+
+// Grid is a width x height rectangle of cells stored row by row.
+type Grid[T any] struct {
+	width, height int
+	cells         []T
+}
+
+func New[T any](width, height int) *Grid[T] {
+	return &Grid[T]{
+		width:  width,
+		height: height,
+		cells:  make([]T, width*height),
+	}
+}
+
+// Parse builds a byte grid from input lines, every line must have the same
+// length.
+func Parse(lines []string) (*Grid[byte], error) {
+	if len(lines) == 0 {
+		return New[byte](0, 0), nil
+	}
+	g := New[byte](len(lines[0]), len(lines))
+	for y, line := range lines {
+		if len(line) != g.width {
+			return nil, fmt.Errorf("line %d: width %d, want %d", y+1, len(line), g.width)
+		}
+		copy(g.Row(y), line)
+	}
+	return g, nil
+}
+
+func (g *Grid[T]) Width() int {
+	return g.width
+}
+
+func (g *Grid[T]) Height() int {
+	return g.height
+}
+
+// Len is the number of cells, width * height.
+func (g *Grid[T]) Len() int {
+	return len(g.cells)
+}
+
+// In reports whether v lies inside the grid.
+func (g *Grid[T]) In(v grid.Vec) bool {
+	return v.X >= 0 && v.X < g.width && v.Y >= 0 && v.Y < g.height
+}
+
+// Get returns the cell at v, or false if v is out of bounds.
+func (g *Grid[T]) Get(v grid.Vec) (T, bool) {
+	if !g.In(v) {
+		var zero T
+		return zero, false
+	}
+	return g.cells[v.Y*g.width+v.X], true
+}
+
+// Set stores t at v, it returns false and does nothing if v is out of bounds.
+func (g *Grid[T]) Set(v grid.Vec, t T) bool {
+	if !g.In(v) {
+		return false
+	}
+	g.cells[v.Y*g.width+v.X] = t
+	return true
+}
+
+// All yields every cell in row-major order.
+func (g *Grid[T]) All() iter.Seq2[grid.Vec, T] {
+	return func(yield func(grid.Vec, T) bool) {
+		for i, c := range g.cells {
+			if !yield(grid.Vec{X: i % g.width, Y: i / g.width}, c) {
+				return
+			}
+		}
+	}
+}
+
+// Neighbours yields the cells at v+offset for every offset that stays inside
+// the grid.
+func (g *Grid[T]) Neighbours(v grid.Vec, offsets []grid.Vec) iter.Seq2[grid.Vec, T] {
+	return func(yield func(grid.Vec, T) bool) {
+		for _, d := range offsets {
+			n := v.Add(d)
+			if !g.In(n) {
+				continue
+			}
+			if !yield(n, g.cells[n.Y*g.width+n.X]) {
+				return
+			}
+		}
+	}
+}
+
+// Count returns how many cells satisfy fn.
+func (g *Grid[T]) Count(fn func(T) bool) int {
+	var n int
+	for _, c := range g.cells {
+		if fn(c) {
+			n++
+		}
+	}
+	return n
+}
+
+// Row returns row y as a slice sharing the grid storage.
+func (g *Grid[T]) Row(y int) []T {
+	return g.cells[y*g.width : (y+1)*g.width]
+}
@@ -0,0 +1,199 @@
+// Package interval keeps sets of integers as sorted, disjoint closed ranges.
+package interval
+
+import (
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/scbizu/aoc2025/internal/checked"
+)
+
+// Interval is the closed integer range [Start, End].
+type Interval struct {
+	Start, End int
+}
+
+// Len is the number of integers in the interval, ok is false if it does not
+// fit an int, which only happens for intervals spanning most of the int range.
+func (iv Interval) Len() (int, bool) {
+	n, ok := checked.Sub(iv.End, iv.Start)
+	if !ok {
+		return 0, false
+	}
+	return checked.Add(n, 1)
+}
+
+// BigLen is Len without the overflow.
+func (iv Interval) BigLen() *big.Int {
+	n := new(big.Int).Sub(big.NewInt(int64(iv.End)), big.NewInt(int64(iv.Start)))
+	return n.Add(n, big.NewInt(1))
+}
+
+func (iv Interval) Contains(n int) bool {
+	return n >= iv.Start && n <= iv.End
+}
+
+// touches reports whether iv and o overlap or sit next to each other, in
+// which case they collapse into one interval of the set.
+func (iv Interval) touches(o Interval) bool {
+	if iv.Start > o.Start {
+		iv, o = o, iv
+	}
+	return o.Start <= iv.End || (iv.End != math.MaxInt && o.Start == iv.End+1)
+}
+
+// IntervalSet is a set of integers stored as sorted intervals, no two of
+// which overlap or are adjacent.
+type IntervalSet struct {
+	ivs []Interval
+}
+
+// New builds a set from intervals in any order, inverted intervals are
+// ignored.
+func New(ivs ...Interval) *IntervalSet {
+	raw := make([]Interval, 0, len(ivs))
+	for _, iv := range ivs {
+		if iv.Start <= iv.End {
+			raw = append(raw, iv)
+		}
+	}
+	sort.Slice(raw, func(i, j int) bool {
+		return raw[i].Start < raw[j].Start
+	})
+	s := &IntervalSet{}
+	for _, iv := range raw {
+		if n := len(s.ivs); n > 0 && s.ivs[n-1].touches(iv) {
+			s.ivs[n-1].End = max(s.ivs[n-1].End, iv.End)
+			continue
+		}
+		s.ivs = append(s.ivs, iv)
+	}
+	return s
+}
+
+// Intervals returns a copy of the sorted intervals.
+func (s *IntervalSet) Intervals() []Interval {
+	return append([]Interval(nil), s.ivs...)
+}
+
+// search returns the index of the first interval ending at or after n.
+func (s *IntervalSet) search(n int) int {
+	return sort.Search(len(s.ivs), func(i int) bool {
+		return s.ivs[i].End >= n
+	})
+}
+
+// Add inserts iv, merging it with every interval it touches.
+func (s *IntervalSet) Add(iv Interval) {
+	if iv.Start > iv.End {
+		return
+	}
+	i := s.search(iv.Start)
+	if i > 0 && s.ivs[i-1].touches(iv) {
+		i--
+	}
+	j := i
+	for j < len(s.ivs) && s.ivs[j].touches(iv) {
+		iv.Start = min(iv.Start, s.ivs[j].Start)
+		iv.End = max(iv.End, s.ivs[j].End)
+		j++
+	}
+	s.ivs = append(s.ivs[:i], append([]Interval{iv}, s.ivs[j:]...)...)
+}
+
+// Remove drops every integer of iv from the set.
+func (s *IntervalSet) Remove(iv Interval) {
+	if iv.Start > iv.End {
+		return
+	}
+	i := s.search(iv.Start)
+	var keep []Interval
+	j := i
+	for ; j < len(s.ivs) && s.ivs[j].Start <= iv.End; j++ {
+		cur := s.ivs[j]
+		if cur.Start < iv.Start {
+			keep = append(keep, Interval{Start: cur.Start, End: iv.Start - 1})
+		}
+		if cur.End > iv.End {
+			keep = append(keep, Interval{Start: iv.End + 1, End: cur.End})
+		}
+	}
+	s.ivs = append(s.ivs[:i], append(keep, s.ivs[j:]...)...)
+}
+
+// Contains reports whether n is in the set, in O(log n).
+func (s *IntervalSet) Contains(n int) bool {
+	i := s.search(n)
+	return i < len(s.ivs) && s.ivs[i].Start <= n
+}
+
+// Find returns the interval of the set holding n.
+func (s *IntervalSet) Find(n int) (Interval, bool) {
+	i := s.search(n)
+	if i < len(s.ivs) && s.ivs[i].Start <= n {
+		return s.ivs[i], true
+	}
+	return Interval{}, false
+}
+
+// Length is the total number of integers covered by the set. It is summed in
+// an int while that is exact and switches to math/big once it overflows.
+func (s *IntervalSet) Length() *big.Int {
+	var n int
+	for i, iv := range s.ivs {
+		l, ok := iv.Len()
+		if ok {
+			var sum int
+			if sum, ok = checked.Add(n, l); ok {
+				n = sum
+			}
+		}
+		if !ok {
+			total := big.NewInt(int64(n))
+			for _, rest := range s.ivs[i:] {
+				total.Add(total, rest.BigLen())
+			}
+			return total
+		}
+	}
+	return big.NewInt(int64(n))
+}
+
+// Union returns a new set with the integers of s or o.
+func (s *IntervalSet) Union(o *IntervalSet) *IntervalSet {
+	return New(append(s.Intervals(), o.ivs...)...)
+}
+
+// Intersect returns a new set with the integers of both s and o.
+func (s *IntervalSet) Intersect(o *IntervalSet) *IntervalSet {
+	res := &IntervalSet{}
+	i, j := 0, 0
+	for i < len(s.ivs) && j < len(o.ivs) {
+		a, b := s.ivs[i], o.ivs[j]
+		lo, hi := max(a.Start, b.Start), min(a.End, b.End)
+		if lo <= hi {
+			res.ivs = append(res.ivs, Interval{Start: lo, End: hi})
+		}
+		if a.End < b.End {
+			i++
+		} else {
+			j++
+		}
+	}
+	return res
+}
+
+// Subtract returns a new set with the integers of s that are not in o.
+func (s *IntervalSet) Subtract(o *IntervalSet) *IntervalSet {
+	res := New(s.ivs...)
+	for _, iv := range o.ivs {
+		res.Remove(iv)
+	}
+	return res
+}
+
+// Complement returns the integers of bounds that are not in the set.
+func (s *IntervalSet) Complement(bounds Interval) *IntervalSet {
+	return New(bounds).Subtract(s)
+}
@@ -0,0 +1,25 @@
+package interval
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestLengthOverflow(t *testing.T) {
+	s := New(
+		Interval{Start: math.MinInt / 2, End: 0},
+		Interval{Start: 10, End: math.MaxInt - 5},
+	)
+	want, _ := new(big.Int).SetString("13835058055282163698", 10)
+	if got := s.Length(); got.Cmp(want) != 0 {
+		t.Errorf("Length() = %s, want %s", got, want)
+	}
+}
+
+func TestLength(t *testing.T) {
+	s := New(Interval{Start: 3, End: 5}, Interval{Start: 10, End: 14}, Interval{Start: 16, End: 20}, Interval{Start: 12, End: 18})
+	if got := s.Length(); got.Cmp(big.NewInt(14)) != 0 {
+		t.Errorf("Length() = %s, want 14", got)
+	}
+}
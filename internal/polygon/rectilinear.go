@@ -0,0 +1,297 @@
+// Package polygon handles simple polygons on the integer grid.
+package polygon
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/magejiCoder/magejiAoc/grid"
+	"github.com/magejiCoder/magejiAoc/math"
+)
+
+// Rectilinear is a simple polygon whose edges are all horizontal or
+// vertical, given by its vertices in order. The polygon is closed: points
+// on its boundary count as inside.
+type Rectilinear struct {
+	vertices []grid.Vec
+	// raster is built on the first rectangle query.
+	raster *Raster
+}
+
+// edge is the closed segment from a to b.
+type edge struct {
+	a, b grid.Vec
+}
+
+func (e edge) horizontal() bool {
+	return e.a.Y == e.b.Y
+}
+
+func (e edge) bounds() (minX, maxX, minY, maxY int) {
+	return min(e.a.X, e.b.X), max(e.a.X, e.b.X), min(e.a.Y, e.b.Y), max(e.a.Y, e.b.Y)
+}
+
+func (e edge) dir() grid.Vec {
+	return grid.Vec{X: e.b.X - e.a.X, Y: e.b.Y - e.a.Y}
+}
+
+// intersects reports whether the two closed segments share a point.
+func (e edge) intersects(o edge) bool {
+	x1, x2, y1, y2 := e.bounds()
+	ox1, ox2, oy1, oy2 := o.bounds()
+	return x1 <= ox2 && ox1 <= x2 && y1 <= oy2 && oy1 <= y2
+}
+
+// on reports whether p lies on the segment.
+func (e edge) on(p grid.Vec) bool {
+	x1, x2, y1, y2 := e.bounds()
+	return p.X >= x1 && p.X <= x2 && p.Y >= y1 && p.Y <= y2
+}
+
+// New checks that vertices form a simple rectilinear polygon: at least four
+// vertices, every edge horizontal or vertical and no two edges touching
+// except consecutive ones at their shared vertex.
+func New(vertices []grid.Vec) (*Rectilinear, error) {
+	if len(vertices) < 4 {
+		return nil, fmt.Errorf("polygon has %d vertices, need at least 4", len(vertices))
+	}
+	p := &Rectilinear{vertices: append([]grid.Vec(nil), vertices...)}
+	edges := p.edges()
+	for i, e := range edges {
+		if e.a == e.b {
+			return nil, fmt.Errorf("vertex %d (%d,%d) repeats", i+1, e.a.X, e.a.Y)
+		}
+		if e.a.X != e.b.X && e.a.Y != e.b.Y {
+			return nil, fmt.Errorf("edge %d (%d,%d)-(%d,%d) is not horizontal or vertical", i+1, e.a.X, e.a.Y, e.b.X, e.b.Y)
+		}
+	}
+	n := len(edges)
+	for i := range edges {
+		// consecutive edges meet at a vertex, they only overlap if the
+		// second one turns back along the first.
+		next := edges[(i+1)%n]
+		if edges[i].dir().X*next.dir().X+edges[i].dir().Y*next.dir().Y < 0 {
+			return nil, fmt.Errorf("edge %d turns back at (%d,%d)", (i+1)%n+1, next.a.X, next.a.Y)
+		}
+	}
+	for i := range edges {
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			if edges[i].intersects(edges[j]) {
+				return nil, fmt.Errorf("edges %d and %d cross", i+1, j+1)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Vertices returns a copy of the vertices.
+func (p *Rectilinear) Vertices() []grid.Vec {
+	return append([]grid.Vec(nil), p.vertices...)
+}
+
+func (p *Rectilinear) edges() []edge {
+	edges := make([]edge, len(p.vertices))
+	for i, v := range p.vertices {
+		edges[i] = edge{a: v, b: p.vertices[(i+1)%len(p.vertices)]}
+	}
+	return edges
+}
+
+// Area is the area enclosed by the edges, by the shoelace formula.
+func (p *Rectilinear) Area() int {
+	var twice int
+	for _, e := range p.edges() {
+		twice += grid.Multiply(e.a, e.b)
+	}
+	return int(math.Abs(twice)) / 2
+}
+
+// Perimeter is the total length of the edges, which is also the number of
+// grid points on the boundary.
+func (p *Rectilinear) Perimeter() int {
+	var n int
+	for _, e := range p.edges() {
+		n += int(math.Abs(e.a.X-e.b.X) + math.Abs(e.a.Y-e.b.Y))
+	}
+	return n
+}
+
+// Tiles is the number of grid points inside or on the polygon, by Pick's
+// theorem.
+func (p *Rectilinear) Tiles() int {
+	return p.Area() + p.Perimeter()/2 + 1
+}
+
+// Contains reports whether v is inside or on the polygon. It casts a ray
+// to the right and counts the vertical edges it crosses, each edge holding
+// its lower end but not its upper one so a ray through a vertex counts once.
+func (p *Rectilinear) Contains(v grid.Vec) bool {
+	var crossings int
+	for _, e := range p.edges() {
+		if e.on(v) {
+			return true
+		}
+		if e.horizontal() || e.a.X <= v.X {
+			continue
+		}
+		if _, _, y1, y2 := e.bounds(); v.Y >= y1 && v.Y < y2 {
+			crossings++
+		}
+	}
+	return crossings%2 == 1
+}
+
+// ContainsRect reports whether every grid point of the axis-aligned
+// rectangle with opposite corners a and b is inside or on the polygon.
+func (p *Rectilinear) ContainsRect(a, b grid.Vec) bool {
+	if p.raster == nil {
+		p.raster = p.Rasterize()
+	}
+	return p.raster.ContainsRect(a, b)
+}
+
+// Raster is the polygon drawn on a compressed grid. Every distinct vertex
+// coordinate gets a row or column of its own and so does every open span
+// between two of them and beyond the outermost ones, so the grid points of a
+// cell are either all inside the polygon or all outside.
+type Raster struct {
+	xs, ys []int
+	inside [][]bool
+	// outside[y][x] counts the outside cells above and left of (x,y).
+	outside [][]int
+}
+
+// Rasterize builds the compressed raster of the polygon.
+func (p *Rectilinear) Rasterize() *Raster {
+	r := &Raster{}
+	xs := make(map[int]struct{})
+	ys := make(map[int]struct{})
+	for _, v := range p.vertices {
+		xs[v.X] = struct{}{}
+		ys[v.Y] = struct{}{}
+	}
+	for x := range xs {
+		r.xs = append(r.xs, x)
+	}
+	for y := range ys {
+		r.ys = append(r.ys, y)
+	}
+	sort.Ints(r.xs)
+	sort.Ints(r.ys)
+
+	w, h := r.Width(), r.Height()
+	r.inside = make([][]bool, h)
+	for cy := range r.inside {
+		r.inside[cy] = make([]bool, w)
+	}
+	// the boundary, then a scanline through the middle of every row, in
+	// doubled coordinates so the middle of a span stays an integer. A cell
+	// is inside from a crossed vertical edge to the next one.
+	edges := p.edges()
+	for _, e := range edges {
+		x1, x2, y1, y2 := e.bounds()
+		for cy := r.row(y1); cy <= r.row(y2); cy++ {
+			for cx := r.col(x1); cx <= r.col(x2); cx++ {
+				r.inside[cy][cx] = true
+			}
+		}
+	}
+	crosses := make([]bool, w)
+	for cy := range r.inside {
+		y := middle(r.ys, cy)
+		for _, e := range edges {
+			if e.horizontal() {
+				continue
+			}
+			if _, _, y1, y2 := e.bounds(); y >= 2*y1 && y < 2*y2 {
+				crosses[r.col(e.a.X)] = !crosses[r.col(e.a.X)]
+			}
+		}
+		in := false
+		for cx := range r.inside[cy] {
+			if crosses[cx] {
+				in = !in
+				crosses[cx] = false
+			}
+			// a span between neighbouring coordinates holds no grid
+			// point, so there is nothing in it to be outside.
+			if in || empty(r.ys, cy) || empty(r.xs, cx) {
+				r.inside[cy][cx] = true
+			}
+		}
+	}
+
+	r.outside = make([][]int, h+1)
+	r.outside[0] = make([]int, w+1)
+	for cy := range r.inside {
+		r.outside[cy+1] = make([]int, w+1)
+		for cx, in := range r.inside[cy] {
+			r.outside[cy+1][cx+1] = r.outside[cy][cx+1] + r.outside[cy+1][cx] - r.outside[cy][cx]
+			if !in {
+				r.outside[cy+1][cx+1]++
+			}
+		}
+	}
+	return r
+}
+
+// middle is a point of compressed cell c, doubled.
+func middle(coords []int, c int) int {
+	i := c / 2
+	switch {
+	case c%2 == 1:
+		return 2 * coords[i]
+	case i == 0:
+		return 2*coords[0] - 1
+	}
+	return 2*coords[i-1] + 1
+}
+
+// empty reports whether compressed cell c is a span without grid points.
+func empty(coords []int, c int) bool {
+	i := c / 2
+	return c%2 == 0 && i > 0 && i < len(coords) && coords[i]-coords[i-1] == 1
+}
+
+// index returns the compressed cell holding coordinate v: 2i+1 for coords[i]
+// itself and 2i for the span before it.
+func index(coords []int, v int) int {
+	i := sort.SearchInts(coords, v)
+	if i < len(coords) && coords[i] == v {
+		return 2*i + 1
+	}
+	return 2 * i
+}
+
+func (r *Raster) col(x int) int {
+	return index(r.xs, x)
+}
+
+func (r *Raster) row(y int) int {
+	return index(r.ys, y)
+}
+
+func (r *Raster) Width() int {
+	return 2*len(r.xs) + 1
+}
+
+func (r *Raster) Height() int {
+	return 2*len(r.ys) + 1
+}
+
+// Inside reports whether the grid points of the compressed cell (cx,cy) are
+// inside or on the polygon, which holds for cells without any.
+func (r *Raster) Inside(cx, cy int) bool {
+	return r.inside[cy][cx]
+}
+
+// ContainsRect is Rectilinear.ContainsRect in O(log n).
+func (r *Raster) ContainsRect(a, b grid.Vec) bool {
+	x1, x2 := r.col(min(a.X, b.X)), r.col(max(a.X, b.X))
+	y1, y2 := r.row(min(a.Y, b.Y)), r.row(max(a.Y, b.Y))
+	o := r.outside
+	return o[y2+1][x2+1]-o[y1][x2+1]-o[y2+1][x1]+o[y1][x1] == 0
+}
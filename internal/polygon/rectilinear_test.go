@@ -0,0 +1,223 @@
+package polygon
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/magejiCoder/magejiAoc/grid"
+)
+
+var dirs = []grid.Vec{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}}
+
+// polyomino grows a random 4-connected set of unit cells in a w x h box and
+// fills its holes.
+func polyomino(r *rand.Rand, w, h int) map[grid.Vec]bool {
+	cells := map[grid.Vec]bool{{X: w / 2, Y: h / 2}: true}
+	list := []grid.Vec{{X: w / 2, Y: h / 2}}
+	for n := 1 + r.Intn(w*h/2+1); len(cells) < n; {
+		c := list[r.Intn(len(list))].Add(dirs[r.Intn(4)])
+		if c.X >= 0 && c.X < w && c.Y >= 0 && c.Y < h && !cells[c] {
+			cells[c] = true
+			list = append(list, c)
+		}
+	}
+	outside := map[grid.Vec]bool{}
+	stack := []grid.Vec{{X: -1, Y: -1}}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if outside[c] || cells[c] || c.X < -1 || c.Y < -1 || c.X > w || c.Y > h {
+			continue
+		}
+		outside[c] = true
+		for _, d := range dirs {
+			stack = append(stack, c.Add(d))
+		}
+	}
+	for x := range w {
+		for y := range h {
+			if c := (grid.Vec{X: x, Y: y}); !outside[c] {
+				cells[c] = true
+			}
+		}
+	}
+	return cells
+}
+
+// outline returns the corners of the cells' boundary in order, or nil if the
+// boundary touches itself, i.e. two cells only meet at a corner.
+func outline(cells map[grid.Vec]bool) []grid.Vec {
+	next := map[grid.Vec][]grid.Vec{}
+	link := func(a, b grid.Vec) {
+		next[a] = append(next[a], b)
+	}
+	for c := range cells {
+		x, y := c.X, c.Y
+		if !cells[grid.Vec{X: x, Y: y - 1}] {
+			link(grid.Vec{X: x, Y: y}, grid.Vec{X: x + 1, Y: y})
+		}
+		if !cells[grid.Vec{X: x + 1, Y: y}] {
+			link(grid.Vec{X: x + 1, Y: y}, grid.Vec{X: x + 1, Y: y + 1})
+		}
+		if !cells[grid.Vec{X: x, Y: y + 1}] {
+			link(grid.Vec{X: x + 1, Y: y + 1}, grid.Vec{X: x, Y: y + 1})
+		}
+		if !cells[grid.Vec{X: x - 1, Y: y}] {
+			link(grid.Vec{X: x, Y: y + 1}, grid.Vec{X: x, Y: y})
+		}
+	}
+	var start grid.Vec
+	for a, bs := range next {
+		if len(bs) > 1 {
+			return nil
+		}
+		start = a
+	}
+	var path []grid.Vec
+	for cur := start; len(path) == 0 || cur != start; cur = next[cur][0] {
+		path = append(path, cur)
+	}
+	if len(path) != len(next) {
+		return nil
+	}
+	var corners []grid.Vec
+	n := len(path)
+	for i, b := range path {
+		a, c := path[(i+n-1)%n], path[(i+1)%n]
+		if b.X-a.X != c.X-b.X || b.Y-a.Y != c.Y-b.Y {
+			corners = append(corners, b)
+		}
+	}
+	return corners
+}
+
+// stretch widens every cell row and column to 1 or 2 units, so some
+// vertex coordinates are neighbours and some have grid points between them.
+func stretch(r *rand.Rand, n int) []int {
+	s := []int{r.Intn(5) - 2}
+	for i := 1; i <= n; i++ {
+		s = append(s, s[i-1]+1+r.Intn(2))
+	}
+	return s
+}
+
+func TestBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for range 2000 {
+		w, h := 1+r.Intn(7), 1+r.Intn(7)
+		cells := polyomino(r, w, h)
+		corners := outline(cells)
+		if corners == nil {
+			continue
+		}
+		sx, sy := stretch(r, w+1), stretch(r, h+1)
+		vs := make([]grid.Vec, len(corners))
+		for i, c := range corners {
+			vs[i] = grid.Vec{X: sx[c.X], Y: sy[c.Y]}
+		}
+		p, err := New(vs)
+		if err != nil {
+			t.Fatalf("New(%v): %v", vs, err)
+		}
+
+		in := func(q grid.Vec) bool {
+			for c := range cells {
+				if q.X >= sx[c.X] && q.X <= sx[c.X+1] && q.Y >= sy[c.Y] && q.Y <= sy[c.Y+1] {
+					return true
+				}
+			}
+			return false
+		}
+		var area, tiles int
+		for c := range cells {
+			area += (sx[c.X+1] - sx[c.X]) * (sy[c.Y+1] - sy[c.Y])
+		}
+		lo := grid.Vec{X: sx[0] - 1, Y: sy[0] - 1}
+		hi := grid.Vec{X: sx[w+1] + 1, Y: sy[h+1] + 1}
+		for x := lo.X; x <= hi.X; x++ {
+			for y := lo.Y; y <= hi.Y; y++ {
+				q := grid.Vec{X: x, Y: y}
+				if p.Contains(q) != in(q) {
+					t.Fatalf("%v: Contains(%v) = %v", vs, q, !in(q))
+				}
+				if in(q) {
+					tiles++
+				}
+			}
+		}
+		if p.Area() != area {
+			t.Fatalf("%v: Area() = %d, want %d", vs, p.Area(), area)
+		}
+		if p.Tiles() != tiles {
+			t.Fatalf("%v: Tiles() = %d, want %d", vs, p.Tiles(), tiles)
+		}
+
+		for k := range 30 {
+			a := grid.Vec{X: lo.X + r.Intn(hi.X-lo.X+1), Y: lo.Y + r.Intn(hi.Y-lo.Y+1)}
+			b := grid.Vec{X: lo.X + r.Intn(hi.X-lo.X+1), Y: lo.Y + r.Intn(hi.Y-lo.Y+1)}
+			if k%2 == 0 {
+				a, b = vs[r.Intn(len(vs))], vs[r.Intn(len(vs))]
+			}
+			want := true
+			for x := min(a.X, b.X); x <= max(a.X, b.X); x++ {
+				for y := min(a.Y, b.Y); y <= max(a.Y, b.Y); y++ {
+					want = want && in(grid.Vec{X: x, Y: y})
+				}
+			}
+			if got := p.ContainsRect(a, b); got != want {
+				t.Fatalf("%v: ContainsRect(%v, %v) = %v, want %v", vs, a, b, got, want)
+			}
+		}
+	}
+}
+
+// TestExample is the day 9 example: the largest rectangle between two red
+// tiles is 50, or 24 when it has to stay inside the polygon.
+func TestExample(t *testing.T) {
+	vs := []grid.Vec{
+		{X: 7, Y: 1}, {X: 11, Y: 1}, {X: 11, Y: 7}, {X: 9, Y: 7},
+		{X: 9, Y: 5}, {X: 2, Y: 5}, {X: 2, Y: 3}, {X: 7, Y: 3},
+	}
+	p, err := New(vs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var largest, inside int
+	for i, a := range vs {
+		for _, b := range vs[i+1:] {
+			area := (max(a.X, b.X) - min(a.X, b.X) + 1) * (max(a.Y, b.Y) - min(a.Y, b.Y) + 1)
+			largest = max(largest, area)
+			if p.ContainsRect(a, b) {
+				inside = max(inside, area)
+			}
+		}
+	}
+	if largest != 50 || inside != 24 {
+		t.Errorf("largest rectangles %d and %d, want 50 and 24", largest, inside)
+	}
+	if got := p.Perimeter(); got != 30 {
+		t.Errorf("Perimeter() = %d, want 30", got)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		vs   []grid.Vec
+		want string
+	}{
+		{[]grid.Vec{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}}, "polygon has 3 vertices, need at least 4"},
+		{[]grid.Vec{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 0}, {X: 0, Y: 2}}, "vertex 2 (2,0) repeats"},
+		{[]grid.Vec{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 1, Y: 3}}, "edge 3 (2,2)-(1,3) is not horizontal or vertical"},
+		{[]grid.Vec{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: -1, Y: 0}, {X: -1, Y: 2}, {X: 0, Y: 2}}, "edge 2 turns back at (4,0)"},
+		// the last edge runs left into the first vertex, the first one
+		// leaves it to the right.
+		{[]grid.Vec{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 5, Y: 2}, {X: 5, Y: 0}}, "edge 1 turns back at (0,0)"},
+		{[]grid.Vec{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 2, Y: 4}, {X: 2, Y: -1}, {X: 0, Y: -1}}, "edges 1 and 4 cross"},
+	}
+	for _, tt := range tests {
+		_, err := New(tt.vs)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("New(%v) error = %v, want %q", tt.vs, err, tt.want)
+		}
+	}
+}
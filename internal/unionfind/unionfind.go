@@ -0,0 +1,83 @@
+// Package unionfind is a disjoint-set forest over the integers [0, n), with
+// union by rank and path compression.
+package unionfind
+
+// UnionFind tracks which of n elements are in the same component.
+type UnionFind struct {
+	parent     []int
+	rank       []int
+	size       []int
+	components int
+}
+
+// New returns n singleton components.
+func New(n int) *UnionFind {
+	u := &UnionFind{
+		parent:     make([]int, n),
+		rank:       make([]int, n),
+		size:       make([]int, n),
+		components: n,
+	}
+	for i := range u.parent {
+		u.parent[i] = i
+		u.size[i] = 1
+	}
+	return u
+}
+
+// Find returns the representative of the component holding x.
+func (u *UnionFind) Find(x int) int {
+	root := x
+	for u.parent[root] != root {
+		root = u.parent[root]
+	}
+	for u.parent[x] != root {
+		u.parent[x], x = root, u.parent[x]
+	}
+	return root
+}
+
+// Union merges the components of a and b. It returns false if they already
+// were one component.
+func (u *UnionFind) Union(a, b int) bool {
+	ra, rb := u.Find(a), u.Find(b)
+	if ra == rb {
+		return false
+	}
+	if u.rank[ra] < u.rank[rb] {
+		ra, rb = rb, ra
+	}
+	u.parent[rb] = ra
+	u.size[ra] += u.size[rb]
+	if u.rank[ra] == u.rank[rb] {
+		u.rank[ra]++
+	}
+	u.components--
+	return true
+}
+
+// Connected reports whether a and b are in the same component.
+func (u *UnionFind) Connected(a, b int) bool {
+	return u.Find(a) == u.Find(b)
+}
+
+// Size is the number of elements in the component holding x.
+func (u *UnionFind) Size(x int) int {
+	return u.size[u.Find(x)]
+}
+
+// Components is the number of components left.
+func (u *UnionFind) Components() int {
+	return u.components
+}
+
+// Sizes returns the size of every component, in no particular order.
+func (u *UnionFind) Sizes() []int {
+	sizes := make([]int, 0, u.components)
+	for i, p := range u.parent {
+		if p == i {
+			sizes = append(sizes, u.size[i])
+		}
+	}
+	return sizes
+}
@@ -0,0 +1,44 @@
+package unionfind
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUnion(t *testing.T) {
+	u := New(7)
+	steps := []struct {
+		a, b       int
+		merged     bool
+		components int
+	}{
+		{0, 1, true, 6},
+		{2, 3, true, 5},
+		{1, 0, false, 5},
+		{1, 3, true, 4},
+		{0, 2, false, 4},
+		{4, 5, true, 3},
+		{6, 6, false, 3},
+	}
+	for _, s := range steps {
+		if got := u.Union(s.a, s.b); got != s.merged {
+			t.Errorf("Union(%d, %d) = %v, want %v", s.a, s.b, got, s.merged)
+		}
+		if got := u.Components(); got != s.components {
+			t.Errorf("after Union(%d, %d): Components() = %d, want %d", s.a, s.b, got, s.components)
+		}
+	}
+	for x, want := range []int{4, 4, 4, 4, 2, 2, 1} {
+		if got := u.Size(x); got != want {
+			t.Errorf("Size(%d) = %d, want %d", x, got, want)
+		}
+	}
+	if !u.Connected(0, 3) || u.Connected(3, 4) {
+		t.Error("Connected disagrees with the merges")
+	}
+	sizes := u.Sizes()
+	slices.Sort(sizes)
+	if want := []int{1, 2, 4}; !slices.Equal(sizes, want) {
+		t.Errorf("Sizes() = %v, want %v", sizes, want)
+	}
+}